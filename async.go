@@ -0,0 +1,228 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Flusher is implemented by loggers and writers that buffer output,
+// Flush blocks until all the buffered output has been written.
+type Flusher interface {
+	Flush() error
+}
+
+// DropCounter is implemented by the loggers that drop entries when they are overloaded,
+// Dropped returns the number of entries dropped so far.
+type DropCounter interface {
+	Dropped() uint64
+}
+
+var (
+	_ Flusher     = (*logger)(nil)
+	_ io.Closer   = (*logger)(nil)
+	_ DropCounter = (*logger)(nil)
+)
+
+// OverflowPolicy decides what an asynchronous logger does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until there is room in the buffer.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop drops the new entry.
+	OverflowDrop
+
+	// OverflowDropLowestLevel drops the buffered entry with the lowest level (for example DebugLevel),
+	// if no buffered entry has a lower level than the new entry, the new entry is dropped.
+	OverflowDropLowestLevel
+)
+
+// WithAsync makes the logger write the formatted entries on a background goroutine,
+// at most bufferSize entries are buffered, policy decides what to do when the buffer is full.
+//
+// The Logger returned by New implements Flusher, io.Closer and DropCounter,
+// call Close (or Flush) before the program exits, otherwise the tail of the log may be lost.
+// The number of the entries dropped by policy is reported by DropCounter, for example
+//
+//	if dc, ok := lg.(log.DropCounter); ok {
+//	    dropped := dc.Dropped()
+//	}
+func WithAsync(bufferSize int, policy OverflowPolicy) Option {
+	return func(o *options) {
+		if bufferSize <= 0 {
+			return
+		}
+		o.async = newAsyncWriter(bufferSize, policy)
+	}
+}
+
+type asyncRecord struct {
+	w     io.Writer
+	level Level
+	data  []byte
+}
+
+type asyncWriter struct {
+	dropped uint64 // atomic
+
+	mu       sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	idle     sync.Cond
+
+	policy  OverflowPolicy
+	ring    []asyncRecord
+	head    int // index of the oldest record
+	n       int // number of records in ring
+	writing bool
+	started bool
+	closed  bool
+	done    chan struct{}
+}
+
+func newAsyncWriter(bufferSize int, policy OverflowPolicy) *asyncWriter {
+	a := &asyncWriter{
+		policy: policy,
+		ring:   make([]asyncRecord, bufferSize),
+		done:   make(chan struct{}),
+	}
+	a.notEmpty.L = &a.mu
+	a.notFull.L = &a.mu
+	a.idle.L = &a.mu
+	return a
+}
+
+// Dropped returns the number of entries dropped because the buffer was full.
+func (a *asyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// write queues p for writing to w, p is copied so the caller can reuse it.
+// After Close, write writes to w synchronously.
+func (a *asyncWriter) write(w io.Writer, level Level, p []byte) error {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	a.mu.Lock()
+	for !a.closed && a.n == len(a.ring) {
+		switch a.policy {
+		case OverflowDrop:
+			a.mu.Unlock()
+			atomic.AddUint64(&a.dropped, 1)
+			return nil
+		case OverflowDropLowestLevel:
+			if !a.evictLocked(level) {
+				a.mu.Unlock()
+				atomic.AddUint64(&a.dropped, 1)
+				return nil
+			}
+			atomic.AddUint64(&a.dropped, 1)
+		default:
+			a.notFull.Wait()
+		}
+	}
+	if a.closed {
+		a.mu.Unlock()
+		_, err := writeLevel(w, level, data)
+		return err
+	}
+	if !a.started {
+		a.started = true
+		go a.run()
+	}
+	a.ring[(a.head+a.n)%len(a.ring)] = asyncRecord{
+		w:     w,
+		level: level,
+		data:  data,
+	}
+	a.n++
+	a.notEmpty.Signal()
+	a.mu.Unlock()
+	return nil
+}
+
+// evictLocked removes the buffered record with the lowest level if its level is lower than level.
+func (a *asyncWriter) evictLocked(level Level) bool {
+	index := -1
+	lowest := level
+	for i := 0; i < a.n; i++ {
+		j := (a.head + i) % len(a.ring)
+		if !isLevelEnabled(a.ring[j].level, lowest) {
+			index, lowest = i, a.ring[j].level
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	for i := index; i < a.n-1; i++ {
+		a.ring[(a.head+i)%len(a.ring)] = a.ring[(a.head+i+1)%len(a.ring)]
+	}
+	a.ring[(a.head+a.n-1)%len(a.ring)] = asyncRecord{}
+	a.n--
+	return true
+}
+
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	for {
+		a.mu.Lock()
+		for a.n == 0 && !a.closed {
+			a.notEmpty.Wait()
+		}
+		if a.n == 0 {
+			a.mu.Unlock()
+			return
+		}
+		record := a.ring[a.head]
+		a.ring[a.head] = asyncRecord{}
+		a.head = (a.head + 1) % len(a.ring)
+		a.n--
+		a.writing = true
+		a.notFull.Signal()
+		a.mu.Unlock()
+
+		if _, err := writeLevel(record.w, record.level, record.data); err != nil {
+			fmt.Fprintf(ConcurrentStderr, "log: failed to write to log, error=%v\n", err)
+		}
+
+		a.mu.Lock()
+		a.writing = false
+		if a.n == 0 {
+			a.idle.Broadcast()
+		}
+		a.mu.Unlock()
+	}
+}
+
+// Flush blocks until all the buffered entries have been written.
+func (a *asyncWriter) Flush() error {
+	a.mu.Lock()
+	for a.n > 0 || a.writing {
+		a.idle.Wait()
+	}
+	a.mu.Unlock()
+	return nil
+}
+
+// Close writes all the buffered entries and stops the background goroutine,
+// entries logged after Close are written synchronously.
+func (a *asyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	started := a.started
+	a.notEmpty.Broadcast()
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+
+	if started {
+		<-a.done
+	}
+	return nil
+}
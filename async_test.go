@@ -0,0 +1,173 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testBlockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *testBlockingWriter) Write(p []byte) (n int, err error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *testBlockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestLogger_Async(t *testing.T) {
+	var buf bytes.Buffer
+	lg := _New([]Option{
+		WithOutput(ConcurrentWriter(&buf)),
+		WithFormatter(testMessageFormatter{}),
+		WithAsync(16, OverflowBlock),
+	})
+	for i := 0; i < 100; i++ {
+		lg.Info("msg")
+	}
+	if err := lg.Close(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if have, want := buf.String(), strings.Repeat("msg\n", 100); have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+		return
+	}
+	if have := lg.Dropped(); have != 0 {
+		t.Errorf("have:%d, want:0", have)
+		return
+	}
+
+	// written synchronously after Close
+	lg.Info("closed")
+	if have, want := buf.String(), strings.Repeat("msg\n", 100)+"closed\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+		return
+	}
+}
+
+func TestAsyncWriter_Flush(t *testing.T) {
+	w := &testBlockingWriter{release: make(chan struct{})}
+	a := newAsyncWriter(4, OverflowBlock)
+	a.write(w, InfoLevel, []byte("1"))
+	a.write(w, InfoLevel, []byte("2"))
+	close(w.release)
+	a.Flush()
+	if have, want := w.String(), "12"; have != want {
+		t.Errorf("have:%s, want:%s", have, want)
+		return
+	}
+	a.Close()
+}
+
+func TestAsyncWriter_OverflowDrop(t *testing.T) {
+	w := &testBlockingWriter{release: make(chan struct{})}
+	a := newAsyncWriter(2, OverflowDrop)
+	a.write(w, InfoLevel, []byte("1"))
+	for a.Dropped() == 0 {
+		a.write(w, InfoLevel, []byte("x"))
+	}
+	close(w.release)
+	a.Close()
+	if have := w.String(); !strings.HasPrefix(have, "1") || len(have) > 3 {
+		t.Errorf("not expected output: %s", have)
+		return
+	}
+}
+
+func TestAsyncWriter_OverflowDropLowestLevel(t *testing.T) {
+	a := newAsyncWriter(3, OverflowDropLowestLevel)
+	a.started = true // keeps the records in the ring
+
+	a.write(nil, ErrorLevel, []byte("error"))
+	a.write(nil, DebugLevel, []byte("debug"))
+	a.write(nil, InfoLevel, []byte("info"))
+	a.write(nil, WarnLevel, []byte("warn")) // evicts debug
+	a.write(nil, DebugLevel, []byte("debug2"))
+	if have := a.Dropped(); have != 2 {
+		t.Errorf("have:%d, want:2", have)
+		return
+	}
+	var have []string
+	for i := 0; i < a.n; i++ {
+		have = append(have, string(a.ring[(a.head+i)%len(a.ring)].data))
+	}
+	if want := "error,info,warn"; strings.Join(have, ",") != want {
+		t.Errorf("have:%v, want:%s", have, want)
+		return
+	}
+}
+
+func TestLogger_Dropped(t *testing.T) {
+	w := &testBlockingWriter{release: make(chan struct{})}
+	var lg Logger = New(WithOutput(w), WithFormatter(testMessageFormatter{}), WithAsync(2, OverflowDrop))
+	dc, ok := lg.(DropCounter)
+	if !ok {
+		t.Fatal("want DropCounter")
+	}
+	for dc.Dropped() == 0 {
+		lg.Info("msg")
+	}
+	close(w.release)
+	lg.(io.Closer).Close()
+}
+
+func TestSetAsync(t *testing.T) {
+	defer _std.setOptions(_std.getOptions())
+
+	w := &testBlockingWriter{release: make(chan struct{})}
+	SetOutput(w)
+	SetFormatter(testMessageFormatter{})
+	if err := SetAsync(2, OverflowDrop); err != nil {
+		t.Fatal(err.Error())
+	}
+	for Dropped() == 0 {
+		Info("msg")
+	}
+	close(w.release)
+	if err := Flush(); err != nil {
+		t.Fatal(err.Error())
+	}
+	written := w.String()
+	if n := strings.Count(written, "msg\n"); n < 2 || strings.Trim(written, "msg\n") != "" {
+		t.Errorf("have:%q, want the buffered entries", written)
+	}
+
+	// synchronous again, the previous asynchronous writer is closed
+	async := _std.getOptions().async
+	Info("async")
+	if err := SetAsync(0, OverflowDrop); err != nil {
+		t.Fatal(err.Error())
+	}
+	if have := w.String(); !strings.HasSuffix(have, "async\n") {
+		t.Errorf("have:%q, want the buffered entry written", have)
+	}
+	if _std.getOptions().async != nil || !async.closed {
+		t.Error("want synchronous")
+	}
+	if have := Dropped(); have != 0 {
+		t.Errorf("have:%d, want:0", have)
+	}
+	Info("sync")
+	if have := w.String(); !strings.HasSuffix(have, "async\nsync\n") {
+		t.Errorf("have:%q, want:sync", have)
+	}
+}
+
+type testMessageFormatter struct{}
+
+func (testMessageFormatter) Format(entry *Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
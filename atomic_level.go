@@ -0,0 +1,73 @@
+package log
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// AtomicLevel is a level that can be changed atomically and shared by loggers, see WithAtomicLevel.
+type AtomicLevel struct {
+	level uint32
+}
+
+// NewAtomicLevel creates an AtomicLevel with level, DebugLevel is used if level is invalid.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	if !isValidLevel(level) {
+		level = DebugLevel
+	}
+	return &AtomicLevel{level: uint32(level)}
+}
+
+// Level returns the current level.
+func (a *AtomicLevel) Level() Level {
+	return Level(atomic.LoadUint32(&a.level))
+}
+
+// SetLevel changes the level, the change is visible to all the loggers sharing a.
+func (a *AtomicLevel) SetLevel(level Level) error {
+	if !isValidLevel(level) {
+		return fmt.Errorf("invalid level: %d", level)
+	}
+	atomic.StoreUint32(&a.level, uint32(level))
+	return nil
+}
+
+// SetLevelString changes the level, see SetLevel.
+func (a *AtomicLevel) SetLevelString(str string) error {
+	level, ok := parseLevelString(str)
+	if !ok {
+		return fmt.Errorf("invalid level string: %q", str)
+	}
+	atomic.StoreUint32(&a.level, uint32(level))
+	return nil
+}
+
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// MarshalText implements encoding.TextMarshaler, see Level.MarshalText.
+func (a *AtomicLevel) MarshalText() ([]byte, error) {
+	return a.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see Level.UnmarshalText.
+func (a *AtomicLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	atomic.StoreUint32(&a.level, uint32(level))
+	return nil
+}
+
+// WithAtomicLevel makes the logger use level instead of its own level, it takes precedence over WithLevel.
+//
+// The loggers derived from the logger (by WithField, WithFields and so on) share level,
+// so SetLevel and SetLevelString of any of them change the level of all of them,
+// including the loggers stored in the contexts by NewContext.
+func WithAtomicLevel(level *AtomicLevel) Option {
+	return func(o *options) {
+		o.atomicLevel = level
+	}
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestAtomicLevel(t *testing.T) {
+	a := NewAtomicLevel(invalidLevel)
+	if have := a.Level(); have != DebugLevel {
+		t.Errorf("have:%v, want:%v", have, DebugLevel)
+	}
+	if err := a.SetLevel(WarnLevel); err != nil || a.Level() != WarnLevel {
+		t.Errorf("have:(%v, %v), want:%v", a.Level(), err, WarnLevel)
+	}
+	if err := a.SetLevel(invalidLevel); err == nil || a.Level() != WarnLevel {
+		t.Errorf("have:(%v, %v), want:%v", a.Level(), err, WarnLevel)
+	}
+	if err := a.SetLevelString("error"); err != nil || a.Level() != ErrorLevel {
+		t.Errorf("have:(%v, %v), want:%v", a.Level(), err, ErrorLevel)
+	}
+	if err := a.SetLevelString("verbose"); err == nil || a.Level() != ErrorLevel {
+		t.Errorf("have:(%v, %v), want:%v", a.Level(), err, ErrorLevel)
+	}
+	if err := a.UnmarshalText([]byte("warn")); err != nil || a.String() != "warning" {
+		t.Errorf("have:(%v, %v), want:warning", a, err)
+	}
+	if text, err := a.MarshalText(); err != nil || string(text) != "warning" {
+		t.Errorf("have:(%s, %v), want:warning", text, err)
+	}
+}
+
+func TestLogger_AtomicLevel(t *testing.T) {
+	var buf bytes.Buffer
+	level := NewAtomicLevel(InfoLevel)
+	root := New(WithOutput(&buf), WithFormatter(testMessageFormatter{}), WithLevel(TraceLevel), WithAtomicLevel(level))
+	child := root.WithField("k", "v")
+	ctx := NewContext(context.Background(), child.WithFields("a", 1))
+
+	root.Debug("root")
+	child.Debug("child")
+	DebugContext(ctx, "context")
+	if have := buf.String(); have != "" {
+		t.Errorf("have:%q, want empty", have)
+	}
+
+	// SetLevel of the child changes the level of the whole tree
+	if err := child.SetLevel(DebugLevel); err != nil {
+		t.Error(err.Error())
+	}
+	root.Debug("root")
+	child.Debug("child")
+	DebugContext(ctx, "context")
+	if have, want := buf.String(), "root\nchild\ncontext\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have := level.Level(); have != DebugLevel {
+		t.Errorf("have:%v, want:%v", have, DebugLevel)
+	}
+
+	buf.Reset()
+	root.SetLevelString("error")
+	child.Warn("child")
+	WarnContext(ctx, "context")
+	if have := buf.String(); have != "" {
+		t.Errorf("have:%q, want empty", have)
+	}
+	if have := loggerLevel(child); have != ErrorLevel {
+		t.Errorf("have:%v, want:%v", have, ErrorLevel)
+	}
+}
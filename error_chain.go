@@ -0,0 +1,121 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FieldsError is implemented by the errors that contribute their own fields to the log entry,
+// the fields of the error with key "error" are rendered as "error.<field key>".
+//
+// If several errors of the chain (see WithoutErrorChain) implement FieldsError,
+// the fields of the outer errors take precedence.
+type FieldsError interface {
+	error
+	LogFields() []Field
+}
+
+// WithoutErrorChain disables the error chain rendering.
+//
+// By default, for a field whose value is an error wrapping other errors
+// (with Unwrap() error or Unwrap() []error), the formatters render the messages of the error and its causes
+// (depth-first, the error itself first) as "<key>_chain", for example
+//
+//	error=read config: open app.yaml: no such file, error_chain=["read config: open app.yaml: no such file","open app.yaml: no such file","no such file"]
+//
+// and the fields contributed by the errors of the chain, see FieldsError.
+func WithoutErrorChain() FormatterOption {
+	return func(o *formatterOptions) {
+		o.noErrorChain = true
+	}
+}
+
+// WithErrorTypes makes the formatters render the types of the errors of the chain as "<key>_types",
+// for example error_types=["*fmt.wrapError","*fs.PathError","syscall.Errno"].
+func WithErrorTypes() FormatterOption {
+	return func(o *formatterOptions) {
+		o.errorTypes = true
+	}
+}
+
+const (
+	_errorChainSuffix = "_chain"
+	_errorTypesSuffix = "_types"
+
+	// _maxErrorChain limits the length of the error chain in case of cyclic errors.
+	_maxErrorChain = 32
+)
+
+// fieldError returns the error value of the field, nil if the value is not an error or is a nil pointer.
+func fieldError(f *Field) error {
+	switch f.Type {
+	case ErrorType, UnknownType:
+		err, _ := f.Value.(error)
+		if err == nil || isNilPointer(err) {
+			return nil
+		}
+		return err
+	default:
+		return nil
+	}
+}
+
+// appendErrorFields appends the chain, the types and the contributed fields of err to list, key is the key of err.
+func (o *formatterOptions) appendErrorFields(list []Field, key string, err error) []Field {
+	chain := errorChain(err)
+	start := len(list)
+	for _, e := range chain {
+		fe, ok := e.(FieldsError)
+		if !ok {
+			continue
+		}
+		for _, f := range fe.LogFields() {
+			if f.Key == "" {
+				continue
+			}
+			f.Key = key + "." + f.Key
+			if containsField(list[start:], f.Key) {
+				continue
+			}
+			list = append(list, f)
+		}
+	}
+	if len(chain) <= 1 {
+		return list
+	}
+	messages := make([]string, len(chain))
+	for i, e := range chain {
+		messages[i] = e.Error()
+	}
+	list = append(list, Object(key+_errorChainSuffix, messages))
+	if o.errorTypes {
+		types := make([]string, len(chain))
+		for i, e := range chain {
+			types[i] = fmt.Sprintf("%T", e)
+		}
+		list = append(list, Object(key+_errorTypesSuffix, types))
+	}
+	return list
+}
+
+// errorChain returns err and its causes in depth-first order,
+// the nil pointers are skipped since calling their methods such as Unwrap and LogFields may panic.
+func errorChain(err error) []error {
+	chain := make([]error, 0, 4)
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil || isNilPointer(err) || len(chain) >= _maxErrorChain {
+			return
+		}
+		chain = append(chain, err)
+		if u, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range u.Unwrap() {
+				walk(e)
+			}
+			return
+		}
+		walk(errors.Unwrap(err))
+	}
+	walk(err)
+	return chain
+}
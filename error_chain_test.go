@@ -0,0 +1,107 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type testFieldsError struct {
+	msg    string
+	fields []Field
+	err    error
+}
+
+func (e *testFieldsError) Error() string      { return e.msg }
+func (e *testFieldsError) LogFields() []Field { return e.fields }
+func (e *testFieldsError) Unwrap() error      { return e.err }
+
+type testJoinError []error
+
+func (e testJoinError) Error() string   { return "join" }
+func (e testJoinError) Unwrap() []error { return e }
+
+func TestErrorChain(t *testing.T) {
+	base := errors.New("base")
+	err := fmt.Errorf("outer: %w", testJoinError{fmt.Errorf("a: %w", base), errors.New("b"), nil})
+	var have []string
+	for _, e := range errorChain(err) {
+		have = append(have, e.Error())
+	}
+	want := []string{"outer: join", "join", "a: base", "base", "b"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have:%v, want:%v", have, want)
+	}
+
+	if have := errorChain(base); len(have) != 1 {
+		t.Errorf("have:%v, want:[base]", have)
+	}
+}
+
+func TestFormatter_ErrorChainNilPointer(t *testing.T) {
+	var buf bytes.Buffer
+	lg := New(WithOutput(&buf), WithFormatter(NewTextFormatter(WithoutFields(TimeField|TraceIdField|LocationField))))
+	lg.Info("msg", "error", (*url.Error)(nil), "fields", (*testFieldsError)(nil),
+		"wrap", &testFieldsError{msg: "wrap", err: (*testFieldsError)(nil)},
+		"join", testJoinError{(*url.Error)(nil), errors.New("b")})
+	want := "level=info, msg=msg, error=<nil>, fields=<nil>, join=join, join_chain=[\"join\",\"b\"], wrap=wrap\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	buf.Reset()
+	lg.SetFormatter(NewJsonFormatter(WithoutFields(TimeField | TraceIdField | LocationField | LevelField)))
+	lg.Info("msg", "error", (*url.Error)(nil), "fields", (*testFieldsError)(nil))
+	want = `{"msg":"msg","error":null,"fields":null}` + "\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
+
+func TestFormatter_ErrorChain(t *testing.T) {
+	inner := &testFieldsError{
+		msg:    "inner",
+		fields: []Field{String("code", "inner_code"), Int("retry", 3)},
+	}
+	outer := &testFieldsError{
+		msg:    "outer: inner",
+		fields: []Field{String("code", "outer_code")},
+		err:    inner,
+	}
+	entry := &Entry{
+		Level:     ErrorLevel,
+		Message:   "msg",
+		FieldList: []Field{Err(outer), {Key: "cause", Value: fmt.Errorf("wrap: %w", errors.New("cause"))}, String("k", "v")},
+	}
+
+	formatter := NewJsonFormatter(WithoutFields(TimeField|TraceIdField|LocationField), WithFieldOrder(InsertionFieldOrder))
+	have, err := formatter.Format(entry)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	want := `{"level":"error","msg":"msg","error":"outer: inner","error.code":"outer_code","error.retry":3,` +
+		`"error_chain":["outer: inner","inner"],"cause":"wrap: cause","cause_chain":["wrap: cause","cause"],"k":"v"}` + "\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	formatter = NewTextFormatter(WithoutFields(TimeField|TraceIdField|LocationField), WithErrorTypes())
+	have, _ = formatter.Format(entry)
+	want = `level=error, msg=msg, cause=wrap: cause, cause_chain=["wrap: cause","cause"], cause_types=["*fmt.wrapError","*errors.errorString"], ` +
+		`error=outer: inner, error.code=outer_code, error.retry=3, error_chain=["outer: inner","inner"], ` +
+		`error_types=["*log.testFieldsError","*log.testFieldsError"], k=v` + "\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	formatter = NewTextFormatter(WithoutFields(TimeField|TraceIdField|LocationField), WithoutErrorChain())
+	have, _ = formatter.Format(entry)
+	want = `level=error, msg=msg, cause=wrap: cause, error=outer: inner, k=v` + "\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
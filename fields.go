@@ -8,18 +8,32 @@ var (
 	_ErrFieldKeyMustNotBeEmpty     error = errors.New("the field key must not be empty")
 )
 
-func combineFields(m map[string]interface{}, fields []interface{}) (map[string]interface{}, error) {
+// Field is a key-value pair of the log entry.
+//
+// The fields passed as alternating keys and values have UnknownType and the value is stored in Value,
+// the fields created by String, Int64, Duration and so on store the value in Integer or String
+// so that it is not boxed into interface{}, use Interface to get the value of any Field.
+type Field struct {
+	Key   string
+	Value interface{}
+
+	Type    FieldType
+	Integer int64
+	String  string
+}
+
+// combineFields returns a new list with fields (alternating keys and values) appended to list,
+// list is not modified. A duplicate key keeps its first position and takes the last value.
+func combineFields(list []Field, fields []interface{}) ([]Field, error) {
 	if len(fields) == 0 {
-		return cloneFields(m), nil
+		return list, nil
 	}
 	if len(fields)&1 != 0 {
-		return cloneFields(m), _ErrNumberOfFieldsMustNotBeOdd
+		return list, _ErrNumberOfFieldsMustNotBeOdd
 	}
 
-	m2 := make(map[string]interface{}, 8+len(m)+len(fields)>>1) // 8 is reserved for the standard field
-	for k, v := range m {
-		m2[k] = v
-	}
+	list2 := make([]Field, len(list), len(list)+len(fields)>>1)
+	copy(list2, list)
 	var (
 		k  string
 		ok bool
@@ -28,25 +42,55 @@ func combineFields(m map[string]interface{}, fields []interface{}) (map[string]i
 		if i&1 == 0 { // key
 			k, ok = v.(string)
 			if !ok {
-				return m2, _ErrTypeOfFieldKeyMustBeString
+				return list2, _ErrTypeOfFieldKeyMustBeString
 			}
 			if k == "" {
-				return m2, _ErrFieldKeyMustNotBeEmpty
+				return list2, _ErrFieldKeyMustNotBeEmpty
 			}
 		} else { // value
-			m2[k] = v
+			list2 = setField(list2, Field{Key: k, Value: v})
 		}
 	}
-	return m2, nil
+	return list2, nil
 }
 
-func cloneFields(fields map[string]interface{}) map[string]interface{} {
+// combineTypedFields is the same as combineFields but for the typed fields.
+func combineTypedFields(list []Field, fields []Field) ([]Field, error) {
 	if len(fields) == 0 {
+		return list, nil
+	}
+	list2 := make([]Field, len(list), len(list)+len(fields))
+	copy(list2, list)
+	var err error
+	for _, f := range fields {
+		if f.Key == "" {
+			err = _ErrFieldKeyMustNotBeEmpty
+			continue
+		}
+		list2 = setField(list2, f)
+	}
+	return list2, err
+}
+
+// setField sets the field in list by key, list must be owned by the caller.
+func setField(list []Field, f Field) []Field {
+	for i := range list {
+		if list[i].Key == f.Key {
+			list[i] = f
+			return list
+		}
+	}
+	return append(list, f)
+}
+
+// fieldsMap converts list to the map of Entry.Fields.
+func fieldsMap(list []Field) map[string]interface{} {
+	if len(list) == 0 {
 		return nil
 	}
-	m := make(map[string]interface{}, len(fields))
-	for k, v := range fields {
-		m[k] = v
+	m := make(map[string]interface{}, 8+len(list)) // 8 is reserved for the standard field
+	for _, f := range list {
+		m[f.Key] = f.Interface()
 	}
 	return m
 }
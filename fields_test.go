@@ -8,10 +8,7 @@ import (
 func TestCombineFields(t *testing.T) {
 	// empty fields
 	{
-		old := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb",
-		}
+		old := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}
 
 		have, err := combineFields(old, nil)
 		if err != nil {
@@ -22,22 +19,10 @@ func TestCombineFields(t *testing.T) {
 			t.Errorf("have:%v, old:%v", have, old)
 			return
 		}
-
-		// check if it is a new one
-		for k := range old {
-			delete(old, k)
-		}
-		if len(have) == len(old) {
-			t.Errorf("want not equal, have:%v, old:%v", have, old)
-			return
-		}
 	}
-	// ddd number of field
+	// odd number of field
 	{
-		old := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb",
-		}
+		old := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}
 
 		have, err := combineFields(old, []interface{}{"kc", "vc", "kd"})
 		if err != _ErrNumberOfFieldsMustNotBeOdd {
@@ -48,15 +33,6 @@ func TestCombineFields(t *testing.T) {
 			t.Errorf("have:%v, old:%v", have, old)
 			return
 		}
-
-		// check if it is a new one
-		for k := range old {
-			delete(old, k)
-		}
-		if len(have) == len(old) {
-			t.Errorf("want not equal, have:%v, old:%v", have, old)
-			return
-		}
 	}
 	// nil old
 	{
@@ -65,10 +41,7 @@ func TestCombineFields(t *testing.T) {
 			t.Error("want nil")
 			return
 		}
-		want := map[string]interface{}{
-			"kb": "vb2",
-			"kc": "vc",
-		}
+		want := []Field{{Key: "kc", Value: "vc"}, {Key: "kb", Value: "vb2"}}
 		if !reflect.DeepEqual(have, want) {
 			t.Errorf("have:%v, want:%v", have, want)
 			return
@@ -76,117 +49,86 @@ func TestCombineFields(t *testing.T) {
 	}
 	// non-nil old
 	{
-		old := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb",
-		}
+		old := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}
 
 		have, err := combineFields(old, []interface{}{"kc", "vc", "kb", "vb2"})
 		if err != nil {
 			t.Error("want nil")
 			return
 		}
-		want := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb2",
-			"kc": "vc",
-		}
+		want := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb2"}, {Key: "kc", Value: "vc"}}
 		if !reflect.DeepEqual(have, want) {
 			t.Errorf("have:%v, want:%v", have, want)
 			return
 		}
 
-		// check if it is a new one
-		if reflect.DeepEqual(have, old) {
-			t.Errorf("want not equal, have:%v, old:%v", have, old)
+		// check if old is not modified
+		if want := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}; !reflect.DeepEqual(old, want) {
+			t.Errorf("old is modified, old:%v", old)
 			return
 		}
 	}
 	// non-nil old with non-string type of key
 	{
-		old := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb",
-		}
+		old := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}
 
 		have, err := combineFields(old, []interface{}{"kc", "vc", "kb", "vb2", 1, 2, "kd", "vd"})
 		if err != _ErrTypeOfFieldKeyMustBeString {
 			t.Error("want equal")
 			return
 		}
-		want := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb2",
-			"kc": "vc",
-		}
+		want := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb2"}, {Key: "kc", Value: "vc"}}
 		if !reflect.DeepEqual(have, want) {
 			t.Errorf("have:%v, want:%v", have, want)
 			return
 		}
 
-		// check if it is a new one
-		if reflect.DeepEqual(have, old) {
-			t.Errorf("want not equal, have:%v, old:%v", have, old)
+		// check if old is not modified
+		if want := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}; !reflect.DeepEqual(old, want) {
+			t.Errorf("old is modified, old:%v", old)
 			return
 		}
 	}
 	// non-nil old with empty key
 	{
-		old := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb",
-		}
+		old := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}
 
 		have, err := combineFields(old, []interface{}{"kc", "vc", "kb", "vb2", "", "vd", "ke", "ve"})
 		if err != _ErrFieldKeyMustNotBeEmpty {
 			t.Error("want equal")
 			return
 		}
-		want := map[string]interface{}{
-			"ka": "va",
-			"kb": "vb2",
-			"kc": "vc",
-		}
+		want := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb2"}, {Key: "kc", Value: "vc"}}
 		if !reflect.DeepEqual(have, want) {
 			t.Errorf("have:%v, want:%v", have, want)
 			return
 		}
 
-		// check if it is a new one
-		if reflect.DeepEqual(have, old) {
-			t.Errorf("want not equal, have:%v, old:%v", have, old)
+		// check if old is not modified
+		if want := []Field{{Key: "ka", Value: "va"}, {Key: "kb", Value: "vb"}}; !reflect.DeepEqual(old, want) {
+			t.Errorf("old is modified, old:%v", old)
 			return
 		}
 	}
 }
 
-func TestCloneFields(t *testing.T) {
-	// nil source
+func TestFieldsMap(t *testing.T) {
+	// nil list
 	{
-		var m map[string]interface{}
-		m2 := cloneFields(m)
-		if m2 != nil {
+		if m := fieldsMap(nil); m != nil {
 			t.Error("want nil")
 			return
 		}
 	}
-	// non-nil source
+	// non-nil list
 	{
-		m := map[string]interface{}{
+		m := fieldsMap([]Field{{Key: "a", Value: "va"}, {Key: "b", Value: "vb"}})
+		want := map[string]interface{}{
 			"a": "va",
 			"b": "vb",
 		}
-		m2 := cloneFields(m)
-		if !reflect.DeepEqual(m, m2) {
-			t.Errorf("m2:%v, m:%v", m2, m)
-			return
-		}
-		// check if it is a new one
-		for k := range m {
-			delete(m, k)
-		}
-		if len(m2) == len(m) {
-			t.Errorf("want not equal, m2:%v, m:%v", m2, m)
+		if !reflect.DeepEqual(m, want) {
+			t.Errorf("have:%v, want:%v", m, want)
 			return
 		}
 	}
@@ -0,0 +1,267 @@
+package log
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// BuiltinField identifies the built-in fields of TextFormatter and JsonFormatter,
+// they can be combined with the bitwise OR operator.
+type BuiltinField uint
+
+const (
+	TimeField BuiltinField = 1 << iota
+	LevelField
+	TraceIdField
+	LocationField
+	MessageField
+
+	// StackField is the stack of Entry.Stack, it is rendered only if the stack is captured, see WithStacktrace.
+	StackField
+)
+
+// FieldOrder is the order in which the formatters render the fields of Entry.Fields,
+// the built-in fields are always rendered first.
+type FieldOrder int
+
+const (
+	// SortedFieldOrder renders the fields sorted by key.
+	SortedFieldOrder FieldOrder = iota
+
+	// InsertionFieldOrder renders the fields in the order they were added to the logger,
+	// the fields of the Entry that are not added by the logger (for example, by a custom formatter wrapper)
+	// are rendered last, sorted by key.
+	InsertionFieldOrder
+)
+
+type FormatterOption func(*formatterOptions)
+
+// WithFieldKey renames the built-in field, for example WithFieldKey(TimeField, "@timestamp").
+func WithFieldKey(field BuiltinField, key string) FormatterOption {
+	return func(o *formatterOptions) {
+		if key == "" {
+			return
+		}
+		if field&TimeField != 0 {
+			o.timeKey = key
+		}
+		if field&LevelField != 0 {
+			o.levelKey = key
+		}
+		if field&TraceIdField != 0 {
+			o.traceIdKey = key
+		}
+		if field&LocationField != 0 {
+			o.locationKey = key
+		}
+		if field&MessageField != 0 {
+			o.messageKey = key
+		}
+		if field&StackField != 0 {
+			o.stackKey = key
+		}
+	}
+}
+
+// WithoutFields omits the built-in fields, for example WithoutFields(TraceIdField|LocationField).
+func WithoutFields(fields BuiltinField) FormatterOption {
+	return func(o *formatterOptions) {
+		if fields&TimeField != 0 {
+			o.timeKey = ""
+		}
+		if fields&LevelField != 0 {
+			o.levelKey = ""
+		}
+		if fields&TraceIdField != 0 {
+			o.traceIdKey = ""
+		}
+		if fields&LocationField != 0 {
+			o.locationKey = ""
+		}
+		if fields&MessageField != 0 {
+			o.messageKey = ""
+		}
+		if fields&StackField != 0 {
+			o.stackKey = ""
+		}
+	}
+}
+
+// WithTimeLocation sets the time zone of the time field, the default is Asia/Shanghai.
+func WithTimeLocation(loc *time.Location) FormatterOption {
+	return func(o *formatterOptions) {
+		if loc == nil {
+			return
+		}
+		o.timeLocation = loc
+	}
+}
+
+// WithTimeLayout sets the layout of the time field, the default is TimeFormatLayout.
+func WithTimeLayout(layout string) FormatterOption {
+	return func(o *formatterOptions) {
+		if layout == "" {
+			return
+		}
+		o.timeLayout = layout
+	}
+}
+
+// WithFieldOrder sets the order of the fields, the default is SortedFieldOrder.
+func WithFieldOrder(order FieldOrder) FormatterOption {
+	return func(o *formatterOptions) {
+		o.fieldOrder = order
+	}
+}
+
+// WithLevelColor makes TextFormatter color the level with the ANSI color of the level, see Level.Color,
+// it is intended for the terminal output and is ignored by JsonFormatter.
+func WithLevelColor() FormatterOption {
+	return func(o *formatterOptions) {
+		o.levelColor = true
+	}
+}
+
+// formatterOptions must be comparable, see isSameFormatter.
+type formatterOptions struct {
+	// the keys of the built-in fields, empty means omitted.
+	timeKey     string
+	levelKey    string
+	traceIdKey  string
+	locationKey string
+	messageKey  string
+	stackKey    string
+
+	timeLocation *time.Location
+	timeLayout   string
+	fieldOrder   FieldOrder
+	noErrorChain bool
+	errorTypes   bool
+	levelColor   bool
+}
+
+func newFormatterOptions(opts []FormatterOption) formatterOptions {
+	o := formatterOptions{
+		timeKey:      fieldKeyTime,
+		levelKey:     fieldKeyLevel,
+		traceIdKey:   fieldKeyTraceId,
+		locationKey:  fieldKeyLocation,
+		messageKey:   fieldKeyMessage,
+		stackKey:     fieldKeyStack,
+		timeLocation: _beijingLocation,
+		timeLayout:   TimeFormatLayout,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&o)
+	}
+	return o
+}
+
+func (o *formatterOptions) formatTime(t time.Time) string {
+	t = t.In(o.timeLocation)
+	if o.timeLayout == TimeFormatLayout {
+		return FormatTimeString(t)
+	}
+	return t.Format(o.timeLayout)
+}
+
+// appendFields appends the fields of the entry to list in the order of o.fieldOrder,
+// the keys that clash with the built-in fields are renamed, see clashFreeKey.
+// The error fields are followed by the fields of their chains, see WithoutErrorChain.
+//
+// The fields come from entry.FieldList, if entry.Fields is not nil, it is respected for compatibility:
+// the fields deleted from it are skipped, the untyped values are taken from it,
+// and the fields only in it are appended in sorted order.
+func (o *formatterOptions) appendFields(list []Field, entry *Entry) []Field {
+	fields := entry.Fields
+	start := len(list)
+	n := 0
+	for _, f := range entry.FieldList {
+		if fields != nil {
+			v, ok := fields[f.Key]
+			if !ok {
+				continue
+			}
+			if f.Type == UnknownType {
+				f.Value = v
+			}
+		}
+		f.Key = o.clashFreeKey(entry, f.Key)
+		list = append(list, f)
+		n++
+		if err := fieldError(&f); err != nil && !o.noErrorChain {
+			list = o.appendErrorFields(list, f.Key, err)
+		}
+	}
+	if n < len(fields) {
+		start2 := len(list)
+		for k, v := range fields {
+			if !containsField(entry.FieldList, k) {
+				f := Field{Key: o.clashFreeKey(entry, k), Value: v}
+				list = append(list, f)
+				if err := fieldError(&f); err != nil && !o.noErrorChain {
+					list = o.appendErrorFields(list, f.Key, err)
+				}
+			}
+		}
+		sortFields(list[start2:])
+	}
+	if o.fieldOrder != InsertionFieldOrder {
+		sortFields(list[start:])
+	}
+	return list
+}
+
+func containsField(list []Field, key string) bool {
+	for i := range list {
+		if list[i].Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// sortFields sorts the fields by key, the small lists are sorted without allocation.
+func sortFields(a []Field) {
+	if len(a) > 32 {
+		// sorts a copy so that a does not escape to heap
+		b := make([]Field, len(a))
+		copy(b, a)
+		sort.Slice(b, func(i, j int) bool { return b[i].Key < b[j].Key })
+		copy(a, b)
+		return
+	}
+	for i := 1; i < len(a); i++ {
+		for j := i; j > 0 && a[j].Key < a[j-1].Key; j-- {
+			a[j], a[j-1] = a[j-1], a[j]
+		}
+	}
+}
+
+// clashFreeKey returns the key that the field with the key is renamed to if it clashes with a built-in field,
+// the new key is "fields.<key>", or "fields.<key>.<n>" (n starts from 2) if the entry already has that key.
+// It does not modify the entry.
+func (o *formatterOptions) clashFreeKey(entry *Entry, key string) string {
+	switch key {
+	case "":
+		return key
+	case o.timeKey, o.levelKey, o.traceIdKey, o.locationKey, o.messageKey:
+	case o.stackKey:
+		if len(entry.Stack) == 0 {
+			return key
+		}
+	default:
+		return key
+	}
+	newKey := "fields." + key
+	for k, i := newKey, 2; ; i++ {
+		if !entry.hasField(k) {
+			return k
+		}
+		k = newKey + "." + strconv.Itoa(i)
+	}
+}
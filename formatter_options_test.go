@@ -0,0 +1,129 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewJsonFormatter(t *testing.T) {
+	formatter := NewJsonFormatter(
+		WithFieldKey(TimeField, "@timestamp"),
+		WithFieldKey(LevelField, "severity"),
+		WithFieldKey(TraceIdField, "trace_id"),
+		WithFieldKey(LocationField, "caller"),
+		WithFieldKey(MessageField, "message"),
+		WithTimeLocation(time.UTC),
+		WithTimeLayout(time.RFC3339Nano),
+	)
+	entry := &Entry{
+		Location: "function(file:line)",
+		Time:     time.Date(2018, time.May, 20, 8, 20, 30, 666000000, time.UTC),
+		Level:    InfoLevel,
+		TraceId:  "trace_id_123456789",
+		Message:  "message_123456789",
+		Fields: map[string]interface{}{
+			"severity": "severity",
+			"msg":      "msg",
+		},
+	}
+	data, err := formatter.Format(entry)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	var have map[string]string
+	if err = json.Unmarshal(data, &have); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	want := map[string]string{
+		"@timestamp":      "2018-05-20T08:20:30.666Z",
+		"severity":        "info",
+		"trace_id":        "trace_id_123456789",
+		"caller":          "function(file:line)",
+		"message":         "message_123456789",
+		"fields.severity": "severity",
+		"msg":             "msg",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("\nhave:%v\nwant:%v", have, want)
+		return
+	}
+}
+
+func TestNewTextFormatter(t *testing.T) {
+	formatter := NewTextFormatter(
+		WithoutFields(TraceIdField|LocationField),
+		WithTimeLocation(time.UTC),
+	)
+	entry := &Entry{
+		Location: "function(file:line)",
+		Time:     time.Date(2018, time.May, 20, 8, 20, 30, 666777888, time.UTC),
+		Level:    InfoLevel,
+		TraceId:  "trace_id_123456789",
+		Message:  "message_123456789",
+		Fields: map[string]interface{}{
+			"location": "location",
+		},
+	}
+	have, err := formatter.Format(entry)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	want := "time=2018-05-20 08:20:30.666, level=info, msg=message_123456789, location=location\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+		return
+	}
+}
+
+func TestNewFormatter_Default(t *testing.T) {
+	if NewTextFormatter() != TextFormatter {
+		t.Error("want equal")
+	}
+	if NewJsonFormatter(nil, WithFieldKey(TimeField, ""), WithTimeLocation(nil), WithTimeLayout("")) != JsonFormatter {
+		t.Error("want equal")
+	}
+}
+
+func TestWithFieldOrder(t *testing.T) {
+	var buf bytes.Buffer
+	lg := _New([]Option{
+		WithOutput(&buf),
+		WithFormatter(NewTextFormatter(WithFieldOrder(InsertionFieldOrder), WithoutFields(TimeField|LocationField))),
+	})
+	lg.WithFields("b", 1, "a", 2).Info("msg", "z", 3, "msg", 4, "y", 5, "z", 6)
+	want := "level=info, request_id=, msg=msg, b=1, a=2, z=6, fields.msg=4, y=5\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	buf.Reset()
+	lg.SetFormatter(NewJsonFormatter(WithFieldOrder(InsertionFieldOrder), WithoutFields(TimeField|LocationField)))
+	lg.Info("msg", "z", 3, "y", 4)
+	want = `{"level":"info","request_id":"","msg":"msg","z":3,"y":4}` + "\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
+
+func TestFormatterOptions_AppendFields(t *testing.T) {
+	opts := newFormatterOptions([]FormatterOption{WithFieldOrder(InsertionFieldOrder)})
+	entry := &Entry{
+		Fields:    map[string]interface{}{"c": 1, "b": 2, "a": 3, "time": 4},
+		FieldList: []Field{{Key: "c", Value: 1}, {Key: "x", Value: 0}, {Key: "time", Value: 4}, {Key: "a", Value: 3}},
+	}
+	list := opts.appendFields(nil, entry)
+	var have []string
+	for _, v := range list {
+		have = append(have, v.Key)
+	}
+	if want := "c,fields.time,a,b"; strings.Join(have, ",") != want {
+		t.Errorf("have:%v, want:%s", have, want)
+	}
+}
@@ -9,13 +9,22 @@ import (
 //
 //  data, _ := json.Marshal(v)
 //  return string(data)
+//
+// The values of the struct fields tagged with `log:"redact"` are replaced by DefaultRedactMask
+// and the ones tagged with `log:"hash"` are replaced by their hash (see WithRedactHash),
+// for example:
+//
+//  type LoginRequest struct {
+//      Username string `json:"username"`
+//      Password string `json:"password" log:"redact"`
+//  }
 func JSON(v interface{}) string {
 	pool := getBytesBufferPool()
 	buffer := pool.Get()
 	defer pool.Put(buffer)
 	buffer.Reset()
 
-	if err := json.NewEncoder(buffer).Encode(v); err != nil {
+	if err := json.NewEncoder(buffer).Encode(redactTagged(v)); err != nil {
 		return ""
 	}
 	data := buffer.Bytes()
@@ -35,13 +44,15 @@ func JSON(v interface{}) string {
 //
 //  data, _ := xml.Marshal(v)
 //  return string(data)
+//
+// The struct fields are redacted by the tags the same way as JSON.
 func XML(v interface{}) string {
 	pool := getBytesBufferPool()
 	buffer := pool.Get()
 	defer pool.Put(buffer)
 	buffer.Reset()
 
-	if err := xml.NewEncoder(buffer).Encode(v); err != nil {
+	if err := xml.NewEncoder(buffer).Encode(redactTagged(v)); err != nil {
 		return ""
 	}
 	return string(buffer.Bytes())
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Hook is called by the logger for every entry with one of the levels returned by Levels,
+// after the fields are combined and before the entry is formatted.
+//
+// Fire can add, change or remove the fields with Entry.SetField and Entry.DeleteField,
+// change the other members of the entry, forward the entry elsewhere (metrics, alerting and so on),
+// or drop the entry by returning ErrDropEntry. Other errors are reported to ConcurrentStderr
+// and the entry is still logged.
+//
+// Entry.Fields is nil when the hooks are fired, use Entry.FieldList instead,
+// the entry must not be retained after Fire returns.
+//
+//	NOTE: Fire is called concurrently, it must be thread-safe.
+type Hook interface {
+	Levels() []Level
+	Fire(*Entry) error
+}
+
+// ErrDropEntry is returned by Hook.Fire to drop the entry, the hooks after it are not fired.
+var ErrDropEntry = errors.New("log: drop entry")
+
+// WithHooks adds the hooks to the logger, the hooks are fired in the order they are added.
+func WithHooks(hooks ...Hook) Option {
+	return func(o *options) {
+		o.hooks = o.hooks.with(hooks)
+	}
+}
+
+type levelHook struct {
+	hook   Hook
+	levels []Level
+}
+
+// hooks is immutable.
+type hooks struct {
+	list []levelHook
+}
+
+func (h *hooks) with(hs []Hook) *hooks {
+	var list []levelHook
+	if h != nil {
+		list = make([]levelHook, len(h.list), len(h.list)+len(hs))
+		copy(list, h.list)
+	}
+	for _, hook := range hs {
+		if hook == nil {
+			continue
+		}
+		list = append(list, levelHook{hook: hook, levels: hook.Levels()})
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return &hooks{list: list}
+}
+
+// fire fires the hooks for the entry and reports whether the entry should be logged.
+func (h *hooks) fire(entry *Entry) bool {
+	if h == nil {
+		return true
+	}
+	for i := range h.list {
+		if !containsLevel(h.list[i].levels, entry.Level) {
+			continue
+		}
+		if err := h.list[i].hook.Fire(entry); err != nil {
+			if err == ErrDropEntry {
+				return false
+			}
+			fmt.Fprintf(ConcurrentStderr, "log: failed to fire hook, error=%v, location=%s\n", err, entry.Location)
+		}
+	}
+	return true
+}
+
+func containsLevel(levels []Level, level Level) bool {
+	for _, v := range levels {
+		if v == level {
+			return true
+		}
+	}
+	return false
+}
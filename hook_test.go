@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testHook struct {
+	levels []Level
+	fire   func(*Entry) error
+
+	mu      sync.Mutex
+	entries []string
+}
+
+func (h *testHook) Levels() []Level { return h.levels }
+
+func (h *testHook) Fire(entry *Entry) error {
+	h.mu.Lock()
+	h.entries = append(h.entries, entry.Message)
+	h.mu.Unlock()
+	if h.fire != nil {
+		return h.fire(entry)
+	}
+	return nil
+}
+
+func TestLogger_Hooks(t *testing.T) {
+	var buf bytes.Buffer
+	redact := &testHook{
+		levels: AllLevels,
+		fire: func(entry *Entry) error {
+			if entry.Fields != nil {
+				t.Error("want nil Fields")
+			}
+			entry.DeleteField("password")
+			entry.SetField(String("hooked", "yes"))
+			return nil
+		},
+	}
+	drop := &testHook{
+		levels: []Level{DebugLevel},
+		fire:   func(*Entry) error { return ErrDropEntry },
+	}
+	alert := &testHook{levels: []Level{FatalLevel, ErrorLevel}}
+	lg := New(
+		WithOutput(&buf),
+		WithFormatter(NewTextFormatter(WithoutFields(TimeField|LocationField|TraceIdField), WithFieldOrder(InsertionFieldOrder))),
+		WithHooks(redact, drop),
+		WithHooks(nil, alert),
+	).WithField("user", "u")
+
+	lg.Error("error", "password", "secret", "k", "v")
+	lg.Info("info")
+	lg.Debug("debug")
+	want := "level=error, msg=error, user=u, k=v, hooked=yes\n" +
+		"level=info, msg=info, user=u, hooked=yes\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	if want := []string{"error", "info", "debug"}; !reflect.DeepEqual(redact.entries, want) {
+		t.Errorf("have:%v, want:%v", redact.entries, want)
+	}
+	if want := []string{"error"}; !reflect.DeepEqual(alert.entries, want) {
+		t.Errorf("have:%v, want:%v", alert.entries, want)
+	}
+
+	// the fields of the logger are not modified by the hook
+	if have := lg.(*logger).fields; !reflect.DeepEqual(have, []Field{{Key: "user", Value: "u"}}) {
+		t.Errorf("have:%v", have)
+	}
+}
+
+func TestLogger_HookError(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+	stderr := ConcurrentStderr
+	ConcurrentStderr = ConcurrentWriter(&errBuf)
+	defer func() { ConcurrentStderr = stderr }()
+
+	lg := _New([]Option{
+		WithOutput(&buf),
+		WithFormatter(testMessageFormatter{}),
+	})
+	lg.AddHooks(&testHook{
+		levels: AllLevels,
+		fire:   func(*Entry) error { return errors.New("hook error") },
+	})
+	lg.Info("msg")
+	if have, want := buf.String(), "msg\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have := errBuf.String(); !strings.HasPrefix(have, "log: failed to fire hook, error=hook error, location=") {
+		t.Errorf("have:%q", have)
+	}
+}
+
+func TestEntry_SetField(t *testing.T) {
+	list := []Field{{Key: "a", Value: 1}, {Key: "b", Value: 2}}
+	entry := &Entry{FieldList: list, Fields: fieldsMap(list)}
+
+	entry.SetField(Int("b", 3))
+	entry.SetField(String("c", "4"))
+	entry.SetField(String("", "ignored"))
+	entry.DeleteField("a")
+	entry.DeleteField("z")
+
+	if want := []Field{Int("b", 3), String("c", "4")}; !reflect.DeepEqual(entry.FieldList, want) {
+		t.Errorf("have:%v, want:%v", entry.FieldList, want)
+	}
+	if want := map[string]interface{}{"b": int64(3), "c": "4"}; !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("have:%v, want:%v", entry.Fields, want)
+	}
+	if want := []Field{{Key: "a", Value: 1}, {Key: "b", Value: 2}}; !reflect.DeepEqual(list, want) {
+		t.Errorf("list is modified: %v", list)
+	}
+}
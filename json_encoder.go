@@ -0,0 +1,259 @@
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonObjectEncoder appends the members of a JSON object to b,
+// the caller writes the enclosing '{' and '}'.
+type jsonObjectEncoder struct {
+	b *bytes.Buffer
+	n int // number of members written
+}
+
+func (e *jsonObjectEncoder) appendKey(key string) {
+	if e.n > 0 {
+		e.b.WriteByte(',')
+	}
+	e.n++
+	appendJSONString(e.b, key)
+	e.b.WriteByte(':')
+}
+
+func (e *jsonObjectEncoder) appendString(key, value string) {
+	e.appendKey(key)
+	appendJSONString(e.b, value)
+}
+
+func (e *jsonObjectEncoder) appendField(f *Field) error {
+	e.appendKey(f.Key)
+	return appendJSONField(e.b, f)
+}
+
+// appendJSONField appends the JSON encoding of the value of f to b.
+func appendJSONField(b *bytes.Buffer, f *Field) error {
+	var scratch [64]byte
+	switch f.Type {
+	case StringType:
+		appendJSONString(b, f.String)
+	case Int64Type:
+		b.Write(strconv.AppendInt(scratch[:0], f.Integer, 10))
+	case Uint64Type:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(f.Integer), 10))
+	case Float64Type:
+		appendJSONFloat(b, math.Float64frombits(uint64(f.Integer)), 64)
+	case BoolType:
+		b.Write(strconv.AppendBool(scratch[:0], f.Integer == 1))
+	case DurationType:
+		b.Write(strconv.AppendInt(scratch[:0], f.Integer, 10))
+	case TimeType:
+		b.WriteByte('"')
+		b.Write(f.time().AppendFormat(scratch[:0], time.RFC3339Nano))
+		b.WriteByte('"')
+	case ErrorType:
+		if f.Value == nil || isNilPointer(f.Value) {
+			b.WriteString("null")
+			return nil
+		}
+		appendJSONString(b, f.Value.(error).Error())
+	case StringerType:
+		if f.Value == nil || isNilPointer(f.Value) {
+			b.WriteString("null")
+			return nil
+		}
+		appendJSONString(b, f.Value.(fmt.Stringer).String())
+	case ObjectType:
+		data, err := json.Marshal(redactTagged(f.Value))
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	default:
+		return appendJSONValue(b, f.Value)
+	}
+	return nil
+}
+
+// appendJSONValue appends the JSON encoding of value to b, the output is the same as encoding/json
+// except that the errors are encoded as their messages and NaN and infinities are encoded as strings.
+// The common types are encoded directly and the others are encoded by encoding/json
+// with the tagged struct fields redacted, see JSON.
+func appendJSONValue(b *bytes.Buffer, value interface{}) error {
+	var scratch [64]byte
+	switch v := value.(type) {
+	case nil:
+		b.WriteString("null")
+	case string:
+		appendJSONString(b, v)
+	case bool:
+		b.Write(strconv.AppendBool(scratch[:0], v))
+	case int:
+		b.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int8:
+		b.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int16:
+		b.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int32:
+		b.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int64:
+		b.Write(strconv.AppendInt(scratch[:0], v, 10))
+	case uint:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint8:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint16:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint32:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint64:
+		b.Write(strconv.AppendUint(scratch[:0], v, 10))
+	case uintptr:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case float32:
+		appendJSONFloat(b, float64(v), 32)
+	case float64:
+		appendJSONFloat(b, v, 64)
+	case time.Time:
+		b.WriteByte('"')
+		b.Write(v.AppendFormat(scratch[:0], time.RFC3339Nano))
+		b.WriteByte('"')
+	case time.Duration:
+		b.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case []byte:
+		b.WriteByte('"')
+		if n := base64.StdEncoding.EncodedLen(len(v)); n <= len(scratch) {
+			base64.StdEncoding.Encode(scratch[:n], v)
+			b.Write(scratch[:n])
+		} else {
+			encoder := base64.NewEncoder(base64.StdEncoding, b)
+			encoder.Write(v)
+			encoder.Close()
+		}
+		b.WriteByte('"')
+	case error:
+		if isNilPointer(v) {
+			b.WriteString("null")
+			return nil
+		}
+		appendJSONString(b, v.Error())
+	case json.Marshaler:
+		if isNilPointer(v) {
+			b.WriteString("null")
+			return nil
+		}
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if bytes.ContainsAny(data, "<>&\u2028\u2029") {
+			var buf bytes.Buffer
+			json.HTMLEscape(&buf, data)
+			data = buf.Bytes()
+		}
+		return json.Compact(b, data)
+	default:
+		data, err := json.Marshal(redactTagged(value))
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	}
+	return nil
+}
+
+// isNilPointer reports whether v is a nil pointer, calling its methods such as MarshalJSON may panic,
+// encoding/json encodes it as null.
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// appendJSONFloat encodes f the same way as encoding/json, except that NaN and infinities are encoded as strings.
+func appendJSONFloat(b *bytes.Buffer, f float64, bits int) {
+	var scratch [64]byte
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		b.WriteByte('"')
+		b.Write(strconv.AppendFloat(scratch[:0], f, 'g', -1, bits))
+		b.WriteByte('"')
+		return
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	data := strconv.AppendFloat(scratch[:0], f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(data); n >= 4 && data[n-4] == 'e' && data[n-3] == '-' && data[n-2] == '0' {
+			data[n-2] = data[n-1]
+			data = data[:n-1]
+		}
+	}
+	b.Write(data)
+}
+
+const _hex = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string to b, the HTML characters <, > and & are escaped the same way as encoding/json.
+func appendJSONString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			case '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteString(`\u00`)
+				b.WriteByte(_hex[c>>4])
+				b.WriteByte(_hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteString(s[start:i])
+			b.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 is LINE SEPARATOR and U+2029 is PARAGRAPH SEPARATOR,
+		// they are valid in JSON but not in JavaScript.
+		if r == '\u2028' || r == '\u2029' {
+			b.WriteString(s[start:i])
+			b.WriteString(`\u202`)
+			b.WriteByte(_hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b.WriteString(s[start:])
+	b.WriteByte('"')
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string { return "stringer" }
+
+type testFieldsStringer struct {
+	A int `json:"a"`
+}
+
+func (testFieldsStringer) String() string { return "stringer" }
+
+type testPtrStringer struct{ s string }
+
+func (s *testPtrStringer) String() string { return s.s }
+
+type testMarshaler struct{ data string }
+
+func (m *testMarshaler) MarshalJSON() ([]byte, error) { return []byte(m.data), nil }
+
+func TestAppendJSONValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, `null`},
+		{"a\"b\\c\n\t\x01<>&\u2028\xff", `"a\"b\\c\n\t\u0001\u003c\u003e\u0026\u2028\ufffd"`},
+		{true, `true`},
+		{-123, `-123`},
+		{int8(-8), `-8`},
+		{int64(math.MinInt64), `-9223372036854775808`},
+		{uint64(math.MaxUint64), `18446744073709551615`},
+		{1.5, `1.5`},
+		{float32(0.1), `0.1`},
+		{1e21, `1e+21`},
+		{1e-7, `1e-7`},
+		{math.NaN(), `"NaN"`},
+		{math.Inf(-1), `"-Inf"`},
+		{time.Date(2018, time.May, 20, 8, 20, 30, 666000000, time.UTC), `"2018-05-20T08:20:30.666Z"`},
+		{1500 * time.Millisecond, `1500000000`},
+		{[]byte("123456789"), `"MTIzNDU2Nzg5"`},
+		{bytes.Repeat([]byte{'a'}, 60), `"` + "YWFh" + string(bytes.Repeat([]byte("YWFh"), 19)) + `"`},
+		{errors.New("error"), `"error"`},
+		{&testError{X: "123456789"}, `"test_error_123456789"`},
+		{json.RawMessage(`{ "code" : 0 }`), `{"code":0}`},
+		{testStringer{}, `{}`},
+		{testFieldsStringer{A: 1}, `{"a":1}`},
+		{(*testError)(nil), `null`},
+		{(*testMarshaler)(nil), `null`},
+		{(*testPtrStringer)(nil), `null`},
+		{&testMarshaler{data: `[1, 2]`}, `[1,2]`},
+		{&testMarshaler{data: `"<a&b>"`}, `"\u003ca\u0026b\u003e"`},
+		{map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{[]int{1, 2}, `[1,2]`},
+	}
+	for _, v := range tests {
+		var buf bytes.Buffer
+		if err := appendJSONValue(&buf, v.value); err != nil {
+			t.Errorf("value:%v, error:%v", v.value, err)
+			continue
+		}
+		if have := buf.String(); have != v.want {
+			t.Errorf("value:%v, have:%s, want:%s", v.value, have, v.want)
+		}
+	}
+
+	// the same as encoding/json
+	for _, value := range []interface{}{
+		"<script>&</script>\u2028", time.Second, testStringer{}, testFieldsStringer{A: 1}, &testMarshaler{data: `{"a":"<b>"}`},
+		time.Date(2018, time.May, 20, 8, 20, 30, 666000000, time.UTC), []byte("<>"), map[string]string{"<": ">"},
+	} {
+		var buf bytes.Buffer
+		if err := appendJSONValue(&buf, value); err != nil {
+			t.Errorf("value:%v, error:%v", value, err)
+			continue
+		}
+		want, _ := json.Marshal(value)
+		if have := buf.String(); have != string(want) {
+			t.Errorf("value:%v, have:%s, want:%s", value, have, want)
+		}
+	}
+
+	// unsupported type
+	var buf bytes.Buffer
+	if err := appendJSONValue(&buf, make(chan int)); err == nil {
+		t.Error("want non-nil error")
+	}
+}
+
+func TestJsonFormatter_FormatOrder(t *testing.T) {
+	entry := &Entry{
+		Location: "function(file:line)",
+		Time:     time.Date(2018, time.May, 20, 8, 20, 30, 666000000, time.UTC),
+		Level:    InfoLevel,
+		TraceId:  "trace_id_123456789",
+		Message:  "message_123456789",
+		Fields: map[string]interface{}{
+			"z":     1,
+			"a":     "a",
+			"level": "level",
+		},
+	}
+	have, err := JsonFormatter.Format(entry)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	want := `{"time":"2018-05-20 16:20:30.666","level":"info","request_id":"trace_id_123456789","location":"function(file:line)","msg":"message_123456789",` +
+		`"a":"a","fields.level":"level","z":1}` + "\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+		return
+	}
+	// entry.Fields is not modified
+	if _, ok := entry.Fields["level"]; !ok || len(entry.Fields) != 3 {
+		t.Errorf("entry.Fields is modified: %v", entry.Fields)
+	}
+}
@@ -1,39 +1,74 @@
 package log
 
-import (
-	"bytes"
-	"encoding/json"
-)
+import "bytes"
 
-var JsonFormatter Formatter = jsonFormatter{}
+var JsonFormatter Formatter = NewJsonFormatter()
 
-type jsonFormatter struct{}
+// NewJsonFormatter creates a Formatter that formats the entry as a JSON object.
+func NewJsonFormatter(opts ...FormatterOption) Formatter {
+	return jsonFormatter{
+		opts: newFormatterOptions(opts),
+	}
+}
+
+type jsonFormatter struct {
+	opts formatterOptions
+}
 
-func (jsonFormatter) Format(entry *Entry) ([]byte, error) {
+func (jsonFormatter) formatsFieldList() {}
+
+// Format encodes the entry directly into entry.Buffer, the built-in fields come first
+// and are followed by entry.Fields in the order of WithFieldOrder, entry.Fields is not modified.
+func (f jsonFormatter) Format(entry *Entry) ([]byte, error) {
 	var buffer *bytes.Buffer
 	if entry.Buffer != nil {
 		buffer = entry.Buffer
 	} else {
 		buffer = bytes.NewBuffer(make([]byte, 0, 16<<10))
 	}
-	var fields map[string]interface{}
-	if fields = entry.Fields; len(fields) > 0 {
-		prefixFieldClashes(fields)
-		for k, v := range fields {
-			if vv, ok := v.(error); ok {
-				fields[k] = vv.Error()
-			}
+	buffer.WriteByte('{')
+	encoder := jsonObjectEncoder{b: buffer}
+	if key := f.opts.timeKey; key != "" {
+		encoder.appendKey(key)
+		if f.opts.timeLayout == TimeFormatLayout {
+			result := FormatTime(entry.Time.In(f.opts.timeLocation))
+			buffer.WriteByte('"')
+			buffer.Write(result[:])
+			buffer.WriteByte('"')
+		} else {
+			appendJSONString(buffer, f.opts.formatTime(entry.Time))
 		}
-	} else {
-		fields = make(map[string]interface{}, 8)
 	}
-	fields[fieldKeyTime] = FormatTimeString(entry.Time.In(_beijingLocation))
-	fields[fieldKeyLevel] = entry.Level.String()
-	fields[fieldKeyTraceId] = entry.TraceId
-	fields[fieldKeyLocation] = entry.Location
-	fields[fieldKeyMessage] = entry.Message
-	if err := json.NewEncoder(buffer).Encode(fields); err != nil {
-		return nil, err
+	if key := f.opts.levelKey; key != "" {
+		encoder.appendString(key, entry.Level.String())
+	}
+	if key := f.opts.traceIdKey; key != "" {
+		encoder.appendString(key, entry.TraceId)
+	}
+	if key := f.opts.locationKey; key != "" {
+		encoder.appendString(key, entry.Location)
+	}
+	if key := f.opts.messageKey; key != "" {
+		encoder.appendString(key, entry.Message)
+	}
+	var array [32]Field
+	fields := f.opts.appendFields(array[:0], entry)
+	for i := range fields {
+		if err := encoder.appendField(&fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	if key := f.opts.stackKey; key != "" && len(entry.Stack) > 0 {
+		encoder.appendKey(key)
+		buffer.WriteByte('[')
+		for i, frame := range entry.Stack {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			appendJSONString(buffer, frame)
+		}
+		buffer.WriteByte(']')
 	}
+	buffer.WriteString("}\n")
 	return buffer.Bytes(), nil
 }
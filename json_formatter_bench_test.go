@@ -0,0 +1,69 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// encodingJsonFormatter is the reflection based implementation replaced by jsonFormatter.
+type encodingJsonFormatter struct{}
+
+func (encodingJsonFormatter) Format(entry *Entry) ([]byte, error) {
+	fields := make(map[string]interface{}, len(entry.Fields)+5)
+	for k, v := range entry.Fields {
+		if vv, ok := v.(error); ok {
+			v = vv.Error()
+		}
+		fields[k] = v
+	}
+	fields[fieldKeyTime] = FormatTimeString(entry.Time.In(_beijingLocation))
+	fields[fieldKeyLevel] = entry.Level.String()
+	fields[fieldKeyTraceId] = entry.TraceId
+	fields[fieldKeyLocation] = entry.Location
+	fields[fieldKeyMessage] = entry.Message
+	if err := json.NewEncoder(entry.Buffer).Encode(fields); err != nil {
+		return nil, err
+	}
+	return entry.Buffer.Bytes(), nil
+}
+
+func benchmarkJsonFormatter(b *testing.B, formatter Formatter) {
+	fields := map[string]interface{}{
+		"string":   "value",
+		"int":      123456789,
+		"float":    3.1415926,
+		"bool":     true,
+		"time":     time.Date(2018, time.May, 20, 8, 20, 30, 666000000, time.UTC),
+		"duration": 1500 * time.Millisecond,
+		"error":    &testError{X: "123456789"},
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		buffer := bytes.NewBuffer(make([]byte, 0, 16<<10))
+		for pb.Next() {
+			buffer.Reset()
+			_, err := formatter.Format(&Entry{
+				Location: "function(file:line)",
+				Time:     time.Now(),
+				Level:    InfoLevel,
+				TraceId:  "trace_id_123456789",
+				Message:  "message_123456789",
+				Fields:   fields,
+				Buffer:   buffer,
+			})
+			if err != nil {
+				b.Fatal(err.Error())
+			}
+		}
+	})
+}
+
+func BenchmarkJsonFormatter(b *testing.B) {
+	benchmarkJsonFormatter(b, JsonFormatter)
+}
+
+func BenchmarkEncodingJsonFormatter(b *testing.B) {
+	benchmarkJsonFormatter(b, encodingJsonFormatter{})
+}
@@ -68,3 +68,28 @@ type testError struct {
 func (e *testError) Error() string {
 	return "test_error_123456789"
 }
+
+func TestJsonFormatter_FormatStack(t *testing.T) {
+	entry := &Entry{
+		Level:     ErrorLevel,
+		Message:   "msg",
+		Stack:     []string{"main.f(main.go:10)", "main.main(main.go:5)"},
+		FieldList: []Field{Int("n", 1)},
+	}
+	formatter := NewJsonFormatter(WithoutFields(TimeField|TraceIdField|LocationField), WithFieldKey(StackField, "stacktrace"))
+	have, err := formatter.Format(entry)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	want := `{"level":"error","msg":"msg","n":1,"stacktrace":["main.f(main.go:10)","main.main(main.go:5)"]}` + "\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	entry.Stack = nil
+	have, _ = formatter.Format(entry)
+	if want := `{"level":"error","msg":"msg","n":1}` + "\n"; string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
@@ -1,8 +1,16 @@
 package log
 
 import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 func init() {
@@ -19,55 +27,287 @@ const (
 	WarnLevel
 	InfoLevel
 	DebugLevel
+
+	// PanicLevel is between FatalLevel and ErrorLevel, its value is after DebugLevel
+	// so that the values of the other levels are not changed, see Severity.
+	PanicLevel
+
+	// TraceLevel is lower than DebugLevel, it is for the very chatty output such as protocol dumps.
+	TraceLevel
 )
 
+// AllLevels contains all the built-in levels from the highest to the lowest, for example for Hook.Levels.
+// The levels registered by RegisterLevel are not included.
+var AllLevels = []Level{FatalLevel, PanicLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel, TraceLevel}
+
 func isValidLevel(level Level) bool {
-	return level >= FatalLevel && level <= DebugLevel
+	_, ok := lookupLevel(level)
+	return ok
 }
 func isLevelEnabled(level, loggerLevel Level) bool {
-	return loggerLevel >= level
+	return loggerLevel.Severity() >= level.Severity()
 }
 
 const (
 	FatalLevelString = "fatal"
+	PanicLevelString = "panic"
 	ErrorLevelString = "error"
 	WarnLevelString  = "warning"
 	InfoLevelString  = "info"
 	DebugLevelString = "debug"
+	TraceLevelString = "trace"
 )
 
 func parseLevelString(str string) (level Level, ok bool) {
-	switch strings.ToLower(str) {
-	case DebugLevelString:
-		return DebugLevel, true
-	case InfoLevelString:
-		return InfoLevel, true
-	case WarnLevelString:
-		return WarnLevel, true
-	case ErrorLevelString:
-		return ErrorLevel, true
-	case FatalLevelString:
-		return FatalLevel, true
-	default:
-		return invalidLevel, false
+	str = strings.ToLower(str)
+	for level := FatalLevel; level < Level(len(_builtinLevels)); level++ {
+		if _builtinLevels[level].name == str {
+			return level, true
+		}
 	}
+	if level, ok = getLevelRegistry().names[str]; ok {
+		return level, true
+	}
+	return invalidLevel, false
 }
 
 type Level uint
 
+var (
+	_ encoding.TextMarshaler   = Level(0)
+	_ encoding.TextUnmarshaler = (*Level)(nil)
+	_ json.Marshaler           = Level(0)
+	_ json.Unmarshaler         = (*Level)(nil)
+	_ flag.Getter              = (*Level)(nil)
+)
+
+// _levelAliases are accepted by ParseLevel besides the level strings.
+var _levelAliases = map[string]Level{
+	"warn": WarnLevel,
+	"err":  ErrorLevel,
+}
+
+// ParseLevel parses the level from text, text is one of the level strings (for example "warning"
+// and the names of the levels registered by RegisterLevel), the aliases "warn" and "err",
+// or the decimal value of a level. text is case-insensitive.
+//
+// The decimal values are the values of the Level constants rather than the severities, they are
+// 1 (FatalLevel), 2 (ErrorLevel), 3 (WarnLevel), 4 (InfoLevel), 5 (DebugLevel), 6 (PanicLevel) and 7 (TraceLevel),
+// the levels registered by RegisterLevel have the values returned by it.
+func ParseLevel(text string) (Level, error) {
+	if level, ok := parseLevelString(text); ok {
+		return level, nil
+	}
+	if level, ok := _levelAliases[strings.ToLower(text)]; ok {
+		return level, nil
+	}
+	if n, err := strconv.ParseUint(text, 10, 64); err == nil && isValidLevel(Level(n)) {
+		return Level(n), nil
+	}
+	return invalidLevel, fmt.Errorf("invalid level: %q", text)
+}
+
+// MarshalText implements encoding.TextMarshaler, the level is marshaled as its string,
+// the zero Level is marshaled as the empty string.
+func (level Level) MarshalText() ([]byte, error) {
+	if level == invalidLevel {
+		return []byte{}, nil
+	}
+	if !isValidLevel(level) {
+		return nil, fmt.Errorf("invalid level: %d", level)
+	}
+	return []byte(level.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, text is parsed by ParseLevel,
+// the empty text is unmarshaled as the zero Level.
+func (level *Level) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*level = invalidLevel
+		return nil
+	}
+	v, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*level = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, the level is marshaled as its string, see MarshalText.
+func (level Level) MarshalJSON() ([]byte, error) {
+	text, err := level.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, data is a JSON string parsed by ParseLevel or a JSON number,
+// null and "" are unmarshaled as the zero Level.
+func (level *Level) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*level = invalidLevel
+		return nil
+	}
+	var text string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	} else {
+		text = string(data)
+	}
+	return level.UnmarshalText([]byte(text))
+}
+
+// Set implements flag.Value, for example
+//
+//	level := log.InfoLevel
+//	flag.Var(&level, "log-level", "the log level")
+func (level *Level) Set(s string) error {
+	v, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*level = v
+	return nil
+}
+
+// Get implements flag.Getter.
+func (level *Level) Get() interface{} {
+	return *level
+}
+
 func (level Level) String() string {
-	switch level {
-	case FatalLevel:
-		return FatalLevelString
-	case ErrorLevel:
-		return ErrorLevelString
-	case WarnLevel:
-		return WarnLevelString
-	case InfoLevel:
-		return InfoLevelString
-	case DebugLevel:
-		return DebugLevelString
-	default:
-		return fmt.Sprintf("unknown_%d", level)
+	if info, ok := lookupLevel(level); ok {
+		return info.name
+	}
+	return fmt.Sprintf("unknown_%d", level)
+}
+
+// Severity returns the ordinal of the level, the higher level has the smaller ordinal.
+// The ordinals of the built-in levels are 100 (FatalLevel), 200 (PanicLevel), 300 (ErrorLevel), 400 (WarnLevel),
+// 500 (InfoLevel), 600 (DebugLevel) and 700 (TraceLevel), the invalid levels have the ordinal 0.
+func (level Level) Severity() int {
+	if info, ok := lookupLevel(level); ok {
+		return info.severity
+	}
+	return 0
+}
+
+// Color is the ANSI color of a level, see WithLevelColor.
+type Color uint8
+
+const (
+	NoColor Color = 0
+
+	ColorRed     Color = 31
+	ColorGreen   Color = 32
+	ColorYellow  Color = 33
+	ColorBlue    Color = 34
+	ColorMagenta Color = 35
+	ColorCyan    Color = 36
+	ColorWhite   Color = 37
+	ColorGray    Color = 90
+)
+
+// Color returns the color of the level.
+func (level Level) Color() Color {
+	if info, ok := lookupLevel(level); ok {
+		return info.color
+	}
+	return NoColor
+}
+
+type levelInfo struct {
+	name     string
+	severity int
+	color    Color
+}
+
+var _builtinLevels = [...]levelInfo{
+	FatalLevel: {name: FatalLevelString, severity: 100, color: ColorRed},
+	PanicLevel: {name: PanicLevelString, severity: 200, color: ColorRed},
+	ErrorLevel: {name: ErrorLevelString, severity: 300, color: ColorRed},
+	WarnLevel:  {name: WarnLevelString, severity: 400, color: ColorYellow},
+	InfoLevel:  {name: InfoLevelString, severity: 500, color: ColorBlue},
+	DebugLevel: {name: DebugLevelString, severity: 600, color: ColorGray},
+	TraceLevel: {name: TraceLevelString, severity: 700, color: ColorGray},
+}
+
+func lookupLevel(level Level) (levelInfo, bool) {
+	if level < Level(len(_builtinLevels)) {
+		return _builtinLevels[level], level != invalidLevel
+	}
+	info, ok := getLevelRegistry().levels[level]
+	return info, ok
+}
+
+// _firstCustomLevel is the value of the first level registered by RegisterLevel,
+// the values in between are reserved for the built-in levels.
+const _firstCustomLevel Level = 1024
+
+// levelRegistry is immutable, RegisterLevel replaces it.
+type levelRegistry struct {
+	levels map[Level]levelInfo
+	names  map[string]Level
+	next   Level
+}
+
+var (
+	_levelRegistryMu    sync.Mutex
+	_levelRegistryPtr   unsafe.Pointer // *levelRegistry
+	_emptyLevelRegistry = &levelRegistry{next: _firstCustomLevel}
+)
+
+func getLevelRegistry() *levelRegistry {
+	ptr := (*levelRegistry)(atomic.LoadPointer(&_levelRegistryPtr))
+	if ptr == nil {
+		return _emptyLevelRegistry
+	}
+	return ptr
+}
+
+// RegisterLevel registers a custom level and returns it, for example
+//
+//	AuditLevel, err := log.RegisterLevel("audit", 450, log.ColorMagenta) // between WarnLevel and InfoLevel
+//
+// name is case-insensitive and must not be used by other levels, it is used by Level.String and SetLevelString.
+// severity is the ordinal of the level (see Level.Severity) and must be positive,
+// the levels with the same severity are enabled and disabled together.
+//
+// RegisterLevel is typically called in the init function, the custom levels can not be unregistered.
+func RegisterLevel(name string, severity int, color Color) (Level, error) {
+	name = strings.ToLower(name)
+	if name == "" {
+		return invalidLevel, errors.New("the level name must not be empty")
+	}
+	if severity <= 0 {
+		return invalidLevel, fmt.Errorf("invalid level severity: %d", severity)
+	}
+
+	_levelRegistryMu.Lock()
+	defer _levelRegistryMu.Unlock()
+
+	if _, ok := parseLevelString(name); ok {
+		return invalidLevel, fmt.Errorf("the level name is already registered: %q", name)
+	}
+	old := getLevelRegistry()
+	r := &levelRegistry{
+		levels: make(map[Level]levelInfo, len(old.levels)+1),
+		names:  make(map[string]Level, len(old.names)+1),
+		next:   old.next + 1,
+	}
+	for k, v := range old.levels {
+		r.levels[k] = v
+	}
+	for k, v := range old.names {
+		r.names[k] = v
 	}
+	level := old.next
+	r.levels[level] = levelInfo{name: name, severity: severity, color: color}
+	r.names[name] = level
+	atomic.StorePointer(&_levelRegistryPtr, unsafe.Pointer(r))
+	return level, nil
 }
@@ -0,0 +1,185 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelHandler returns an http.Handler that reports and changes the level of the loggers at runtime,
+// the standard logger is used if no logger is specified.
+//
+// GET reports the current level (the level of the first logger):
+//
+//	{"level":"info"}
+//
+// PUT and POST change the level of all the loggers, the request is a JSON object (Content-Type: application/json)
+// or the form values with the same names, the level is parsed by ParseLevel:
+//
+//	{"level":"debug","ttl":"10m"}
+//
+// If ttl (parsed by time.ParseDuration) is specified, the levels are reverted after it,
+// the response reports the time of the revert:
+//
+//	{"level":"debug","expires":"2018-05-20T08:30:30Z"}
+//
+// A new successful change cancels the pending revert (a rejected one keeps it), if it also has a ttl,
+// the levels are reverted to the ones before the first temporary change.
+//
+//	NOTE: the handler should be mounted on an internal admin endpoint, it does not authenticate the requests.
+func LevelHandler(loggers ...Logger) http.Handler {
+	if len(loggers) == 0 {
+		loggers = []Logger{_std}
+	}
+	return &levelHandler{
+		loggers: loggers,
+	}
+}
+
+type levelHandler struct {
+	loggers []Logger
+
+	mu           sync.Mutex
+	timer        *time.Timer
+	revertLevels []Level // the levels to revert to, nil if there is no pending revert
+	expires      time.Time
+}
+
+type levelHandlerRequest struct {
+	Level *Level `json:"level"`
+	TTL   string `json:"ttl,omitempty"`
+}
+
+type levelHandlerResponse struct {
+	Level   Level      `json:"level,omitempty"`
+	Expires *time.Time `json:"expires,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeLevel(w, http.StatusOK)
+	case http.MethodPut, http.MethodPost:
+		level, ttl, err := parseLevelHandlerRequest(r)
+		if err != nil {
+			writeLevelHandlerResponse(w, http.StatusBadRequest, &levelHandlerResponse{Error: err.Error()})
+			return
+		}
+		if err = h.setLevel(level, ttl); err != nil {
+			writeLevelHandlerResponse(w, http.StatusBadRequest, &levelHandlerResponse{Error: err.Error()})
+			return
+		}
+		h.writeLevel(w, http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelHandlerResponse(w, http.StatusMethodNotAllowed, &levelHandlerResponse{Error: "method not allowed: " + r.Method})
+	}
+}
+
+func (h *levelHandler) writeLevel(w http.ResponseWriter, status int) {
+	h.mu.Lock()
+	resp := levelHandlerResponse{
+		Level: loggerLevel(h.loggers[0]),
+	}
+	if h.revertLevels != nil {
+		expires := h.expires
+		resp.Expires = &expires
+	}
+	h.mu.Unlock()
+	writeLevelHandlerResponse(w, status, &resp)
+}
+
+func writeLevelHandlerResponse(w http.ResponseWriter, status int, resp *levelHandlerResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func parseLevelHandlerRequest(r *http.Request) (level Level, ttl time.Duration, err error) {
+	var req levelHandlerRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return invalidLevel, 0, fmt.Errorf("invalid request body: %v", err)
+		}
+	} else {
+		if str := r.FormValue("level"); str != "" {
+			req.Level = new(Level)
+			if err = req.Level.UnmarshalText([]byte(str)); err != nil {
+				return invalidLevel, 0, err
+			}
+		}
+		req.TTL = r.FormValue("ttl")
+	}
+	if req.Level == nil {
+		return invalidLevel, 0, fmt.Errorf("level is required")
+	}
+	if !isValidLevel(*req.Level) {
+		return invalidLevel, 0, fmt.Errorf("invalid level: %d", *req.Level)
+	}
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil {
+			return invalidLevel, 0, fmt.Errorf("invalid ttl: %v", err)
+		}
+		if ttl < 0 {
+			return invalidLevel, 0, fmt.Errorf("invalid ttl: %s", req.TTL)
+		}
+	}
+	return *req.Level, ttl, nil
+}
+
+func (h *levelHandler) setLevel(level Level, ttl time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revertLevels := h.revertLevels
+	if ttl > 0 && revertLevels == nil {
+		revertLevels = make([]Level, len(h.loggers))
+		for i, lg := range h.loggers {
+			revertLevels[i] = loggerLevel(lg)
+		}
+	}
+	for _, lg := range h.loggers {
+		if err := lg.SetLevel(level); err != nil {
+			return err // the pending revert is kept, it also reverts the loggers changed so far
+		}
+	}
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+		h.revertLevels = nil
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	h.revertLevels = revertLevels
+	h.expires = time.Now().Add(ttl).UTC()
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.timer != timer { // canceled
+			return
+		}
+		for i, lg := range h.loggers {
+			if isValidLevel(h.revertLevels[i]) {
+				lg.SetLevel(h.revertLevels[i])
+			}
+		}
+		h.timer = nil
+		h.revertLevels = nil
+	})
+	h.timer = timer
+	return nil
+}
+
+// loggerLevel returns the level of lg, invalidLevel if it is unknown.
+func loggerLevel(lg Logger) Level {
+	if l, ok := lg.(*logger); ok {
+		return l.getOptions().getLevel()
+	}
+	return invalidLevel
+}
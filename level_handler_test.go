@@ -0,0 +1,132 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testLevelHandlerDo(t *testing.T, h http.Handler, method, contentType, body string) (int, levelHandlerResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/log/level", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp levelHandlerResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Errorf("invalid response: %s", rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+func TestLevelHandler(t *testing.T) {
+	lg1 := New(WithLevel(InfoLevel))
+	lg2 := New(WithLevel(WarnLevel))
+	h := LevelHandler(lg1, lg2)
+
+	code, resp := testLevelHandlerDo(t, h, http.MethodGet, "", "")
+	if code != http.StatusOK || resp.Level != InfoLevel || resp.Expires != nil {
+		t.Errorf("have:(%d, %+v)", code, resp)
+	}
+
+	code, resp = testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":"debug"}`)
+	if code != http.StatusOK || resp.Level != DebugLevel || resp.Expires != nil {
+		t.Errorf("have:(%d, %+v)", code, resp)
+	}
+	if have := loggerLevel(lg2); have != DebugLevel {
+		t.Errorf("have:%v, want:%v", have, DebugLevel)
+	}
+
+	code, resp = testLevelHandlerDo(t, h, http.MethodPost, "application/x-www-form-urlencoded", `level=err`)
+	if code != http.StatusOK || resp.Level != ErrorLevel {
+		t.Errorf("have:(%d, %+v)", code, resp)
+	}
+
+	for _, v := range []struct {
+		method      string
+		contentType string
+		body        string
+		code        int
+	}{
+		{http.MethodPut, "application/json", `{"level":"verbose"}`, http.StatusBadRequest},
+		{http.MethodPut, "application/json", `{"ttl":"1m"}`, http.StatusBadRequest},
+		{http.MethodPut, "application/json", `{"level":""}`, http.StatusBadRequest},
+		{http.MethodPut, "application/json", `{"level":"debug","ttl":"1x"}`, http.StatusBadRequest},
+		{http.MethodPut, "application/json", `{"level":"debug","ttl":"-1m"}`, http.StatusBadRequest},
+		{http.MethodPut, "application/json", `{`, http.StatusBadRequest},
+		{http.MethodPost, "application/x-www-form-urlencoded", `ttl=1m`, http.StatusBadRequest},
+		{http.MethodDelete, "", "", http.StatusMethodNotAllowed},
+	} {
+		code, resp := testLevelHandlerDo(t, h, v.method, v.contentType, v.body)
+		if code != v.code || resp.Error == "" {
+			t.Errorf("request:%s %s, have:(%d, %+v), want:%d", v.method, v.body, code, resp, v.code)
+		}
+	}
+	if have := loggerLevel(lg1); have != ErrorLevel {
+		t.Errorf("have:%v, want:%v", have, ErrorLevel)
+	}
+}
+
+func TestLevelHandler_TTL(t *testing.T) {
+	lg1 := New(WithLevel(InfoLevel))
+	lg2 := New(WithLevel(WarnLevel))
+	h := LevelHandler(lg1, lg2)
+
+	// the second change with ttl reverts to the levels before the first one
+	testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":"debug","ttl":"1h"}`)
+	code, resp := testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":"trace","ttl":"20ms"}`)
+	if code != http.StatusOK || resp.Level != TraceLevel || resp.Expires == nil {
+		t.Errorf("have:(%d, %+v)", code, resp)
+	}
+	if have := loggerLevel(lg2); have != TraceLevel {
+		t.Errorf("have:%v, want:%v", have, TraceLevel)
+	}
+	for deadline := time.Now().Add(5 * time.Second); loggerLevel(lg2) != WarnLevel && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if have1, have2 := loggerLevel(lg1), loggerLevel(lg2); have1 != InfoLevel || have2 != WarnLevel {
+		t.Errorf("have:(%v, %v), want:(%v, %v)", have1, have2, InfoLevel, WarnLevel)
+	}
+	if _, resp = testLevelHandlerDo(t, h, http.MethodGet, "", ""); resp.Expires != nil {
+		t.Errorf("have:%+v", resp)
+	}
+
+	// a change without ttl cancels the revert
+	testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":"debug","ttl":"20ms"}`)
+	testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":"error"}`)
+	time.Sleep(50 * time.Millisecond)
+	if have := loggerLevel(lg1); have != ErrorLevel {
+		t.Errorf("have:%v, want:%v", have, ErrorLevel)
+	}
+
+	// a rejected change keeps the revert
+	testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":"debug","ttl":"20ms"}`)
+	if code, _ := testLevelHandlerDo(t, h, http.MethodPut, "application/json", `{"level":""}`); code != http.StatusBadRequest {
+		t.Errorf("have:%d, want:%d", code, http.StatusBadRequest)
+	}
+	if have := loggerLevel(lg1); have != DebugLevel {
+		t.Errorf("have:%v, want:%v", have, DebugLevel)
+	}
+	for deadline := time.Now().Add(5 * time.Second); loggerLevel(lg1) != ErrorLevel && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if have := loggerLevel(lg1); have != ErrorLevel {
+		t.Errorf("have:%v, want:%v", have, ErrorLevel)
+	}
+}
+
+func TestLevelHandler_Std(t *testing.T) {
+	defer SetLevel(loggerLevel(_std))
+	SetLevel(InfoLevel)
+
+	code, resp := testLevelHandlerDo(t, LevelHandler(), http.MethodGet, "", "")
+	if code != http.StatusOK || resp.Level != InfoLevel {
+		t.Errorf("have:(%d, %+v)", code, resp)
+	}
+}
@@ -0,0 +1,171 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// WithLevelOverrides sets the levels of the entries logged by the specified packages or functions,
+// they take precedence over the logger level, for example
+//
+//	WithLevel(InfoLevel),
+//	WithLevelOverrides(map[string]Level{
+//	    "github.com/ourorg/payments/...":           DebugLevel, // the package and its sub-packages
+//	    "github.com/ourorg/payments/client":        WarnLevel,  // only the package
+//	    "github.com/ourorg/payments/client.(*Conn)": TraceLevel, // the functions with the prefix
+//	})
+//
+// The overrides are matched against the full name of the function that calls the logger,
+// the longest matched pattern wins. The overrides can be changed at runtime by SetLevelOverrides.
+func WithLevelOverrides(overrides map[string]Level) Option {
+	return func(o *options) {
+		o.levelOverrides = newLevelOverrides(overrides)
+	}
+}
+
+// SetLevelOverrides replaces the level overrides of the logger, see WithLevelOverrides.
+// The change is visible to the loggers derived from the logger if it already has level overrides.
+func (l *logger) SetLevelOverrides(overrides map[string]Level) {
+	if lo := l.getOptions().levelOverrides; lo != nil {
+		lo.store(overrides)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	opts := *l.getOptions()
+	if opts.levelOverrides != nil {
+		opts.levelOverrides.store(overrides)
+		return
+	}
+	opts.levelOverrides = newLevelOverrides(overrides)
+	l.setOptions(&opts)
+}
+
+// levelOverrides holds the current levelOverrideTable, it is shared by the derived loggers.
+type levelOverrides struct {
+	table unsafe.Pointer // *levelOverrideTable
+}
+
+func newLevelOverrides(overrides map[string]Level) *levelOverrides {
+	lo := &levelOverrides{}
+	lo.store(overrides)
+	return lo
+}
+
+func (lo *levelOverrides) store(overrides map[string]Level) {
+	atomic.StorePointer(&lo.table, unsafe.Pointer(newLevelOverrideTable(overrides)))
+}
+
+func (lo *levelOverrides) load() *levelOverrideTable {
+	if lo == nil {
+		return nil
+	}
+	t := (*levelOverrideTable)(atomic.LoadPointer(&lo.table))
+	if len(t.patterns) == 0 {
+		return nil
+	}
+	return t
+}
+
+type levelOverridePattern struct {
+	pattern string
+	tree    bool // pattern ends with "/..."
+	level   Level
+}
+
+// levelOverrideTable is immutable except the cache.
+type levelOverrideTable struct {
+	patterns []levelOverridePattern
+	cache    sync.Map // map[uintptr]Level, invalidLevel means no override
+}
+
+func newLevelOverrideTable(overrides map[string]Level) *levelOverrideTable {
+	t := &levelOverrideTable{
+		patterns: make([]levelOverridePattern, 0, len(overrides)),
+	}
+	for pattern, level := range overrides {
+		if pattern == "" || !isValidLevel(level) {
+			continue
+		}
+		p := levelOverridePattern{pattern: pattern, level: level}
+		if strings.HasSuffix(pattern, "/...") {
+			p.pattern, p.tree = strings.TrimSuffix(pattern, "/..."), true
+		}
+		t.patterns = append(t.patterns, p)
+	}
+	return t
+}
+
+// lookup returns the level of the function at pc, ok is false if there is no override for it.
+func (t *levelOverrideTable) lookup(pc uintptr) (level Level, ok bool) {
+	if v, ok := t.cache.Load(pc); ok {
+		level = v.(Level)
+		return level, level != invalidLevel
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		level = t.match(fn.Name())
+	}
+	t.cache.Store(pc, level)
+	return level, level != invalidLevel
+}
+
+func (t *levelOverrideTable) match(funcName string) Level {
+	pkg := funcPackage(funcName)
+	var (
+		level Level
+		best  = -1
+	)
+	for i := range t.patterns {
+		p := &t.patterns[i]
+		if score := p.score(); score > best && p.matches(funcName, pkg) {
+			level, best = p.level, score
+		}
+	}
+	return level
+}
+
+// score is the specificity of the pattern, the longer pattern is more specific,
+// and the package pattern is more specific than the tree pattern of the same package.
+func (p *levelOverridePattern) score() int {
+	if p.tree {
+		return 2 * len(p.pattern)
+	}
+	return 2*len(p.pattern) + 1
+}
+
+// enabled reports whether the entry with level logged at pc is enabled, the sinks level still applies.
+func (t *levelOverrideTable) enabled(opts *options, pc uintptr, level Level) bool {
+	overrideLevel, ok := t.lookup(pc)
+	if !ok {
+		return opts.isEnabled(level)
+	}
+	if !isLevelEnabled(level, overrideLevel) {
+		return false
+	}
+	return opts.sinks == nil || isLevelEnabled(level, opts.sinks.level)
+}
+
+func (p *levelOverridePattern) matches(funcName, pkg string) bool {
+	if p.tree {
+		return pkg == p.pattern || strings.HasPrefix(pkg, p.pattern+"/")
+	}
+	if pkg == p.pattern {
+		return true
+	}
+	// function prefix, the pattern must contain the package path and a part of the function name
+	return len(p.pattern) > len(pkg) && strings.HasPrefix(funcName, p.pattern)
+}
+
+// funcPackage returns the package path of the function full name,
+// for example "github.com/ourorg/payments" for "github.com/ourorg/payments.(*Client).Do".
+func funcPackage(funcName string) string {
+	i := strings.LastIndexByte(funcName, '/')
+	if j := strings.IndexByte(funcName[i+1:], '.'); j >= 0 {
+		return funcName[:i+1+j]
+	}
+	return funcName
+}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLevelOverrideTable_Match(t *testing.T) {
+	table := newLevelOverrideTable(map[string]Level{
+		"github.com/ourorg/payments/...":            DebugLevel,
+		"github.com/ourorg/payments/client":         WarnLevel,
+		"github.com/ourorg/payments/client.(*Conn)": TraceLevel,
+		"github.com/ourorg/orders":                  ErrorLevel,
+		"github.com/ourorg/invalid":                 invalidLevel,
+		"":                                          InfoLevel,
+	})
+	tests := []struct {
+		funcName string
+		level    Level
+	}{
+		{"github.com/ourorg/payments.Pay", DebugLevel},
+		{"github.com/ourorg/payments/refund.(*Service).Refund.func1", DebugLevel},
+		{"github.com/ourorg/payments/client.New", WarnLevel},
+		{"github.com/ourorg/payments/client.(*Conn).Write", TraceLevel},
+		{"github.com/ourorg/payments/client/internal.F", DebugLevel},
+		{"github.com/ourorg/paymentsx.F", invalidLevel},
+		{"github.com/ourorg/orders.F", ErrorLevel},
+		{"github.com/ourorg/orders/sub.F", invalidLevel},
+		{"github.com/ourorg/invalid.F", invalidLevel},
+		{"main.main", invalidLevel},
+	}
+	for _, v := range tests {
+		if have := table.match(v.funcName); have != v.level {
+			t.Errorf("funcName:%s, have:%v, want:%v", v.funcName, have, v.level)
+		}
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		funcName string
+		pkg      string
+	}{
+		{"github.com/ourorg/payments.(*Client).Do", "github.com/ourorg/payments"},
+		{"github.com/ourorg/payments.Pay.func1", "github.com/ourorg/payments"},
+		{"gopkg.in/yaml.v2.Unmarshal", "gopkg.in/yaml"},
+		{"main.main", "main"},
+		{"main", "main"},
+	}
+	for _, v := range tests {
+		if have := funcPackage(v.funcName); have != v.pkg {
+			t.Errorf("funcName:%s, have:%s, want:%s", v.funcName, have, v.pkg)
+		}
+	}
+}
+
+func testLevelOverridesQuiet(lg Logger) {
+	lg.Info("quiet")
+}
+
+func TestLogger_LevelOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	lg := _New([]Option{
+		WithOutput(&buf),
+		WithFormatter(testMessageFormatter{}),
+		WithLevel(InfoLevel),
+		WithLevelOverrides(map[string]Level{
+			"github.com/KeKe-Li/log/...":                     DebugLevel,
+			"github.com/KeKe-Li/log.testLevelOverridesQuiet": WarnLevel,
+		}),
+	})
+	lg2 := lg.WithField("k", "v")
+
+	lg.Debug("debug")
+	lg.Trace("trace")
+	testLevelOverridesQuiet(lg)
+	lg2.Debug("debug2")
+	if have, want := buf.String(), "debug\ndebug2\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	// change at runtime, the derived logger is affected
+	buf.Reset()
+	lg.SetLevelOverrides(map[string]Level{"github.com/KeKe-Li/log": ErrorLevel})
+	lg.Warn("warn")
+	lg2.Warn("warn2")
+	lg2.Error("error2")
+	testLevelOverridesQuiet(lg)
+	if have, want := buf.String(), "error2\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	// no overrides
+	buf.Reset()
+	lg.SetLevelOverrides(nil)
+	lg.Debug("debug")
+	testLevelOverridesQuiet(lg)
+	if have, want := buf.String(), "quiet\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	// SetLevelOverrides on the logger without overrides
+	buf.Reset()
+	lg3 := _New([]Option{WithOutput(&buf), WithFormatter(testMessageFormatter{}), WithLevel(InfoLevel)})
+	lg3.SetLevelOverrides(map[string]Level{"github.com/KeKe-Li/log": DebugLevel})
+	lg3.DebugF("debugF")
+	if have, want := buf.String(), "debugF\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+}
+
+func TestLogger_LevelOverridesLocation(t *testing.T) {
+	var entry Entry
+	lg := New(
+		WithFormatter(&testEntryFormatter{entry: &entry}),
+		WithLevelOverrides(map[string]Level{"github.com/KeKe-Li/log": DebugLevel}),
+	)
+	lg.Info("msg")
+	if want := "log.TestLogger_LevelOverridesLocation("; !strings.HasPrefix(entry.Location, want) {
+		t.Errorf("have:%s, want prefix:%s", entry.Location, want)
+	}
+}
@@ -1,7 +1,13 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"sync/atomic"
 	"testing"
+	"unsafe"
 )
 
 func TestIsValidLevel(t *testing.T) {
@@ -42,7 +48,15 @@ func TestIsValidLevel(t *testing.T) {
 			false,
 		},
 		{
-			6,
+			PanicLevel,
+			true,
+		},
+		{
+			TraceLevel,
+			true,
+		},
+		{
+			8,
 			false,
 		},
 	}
@@ -88,12 +102,44 @@ func TestIsLevelEnabled(t *testing.T) {
 			true,
 		},
 
+		// level is panic
+		{
+			PanicLevel,
+			FatalLevel,
+			false,
+		},
+		{
+			PanicLevel,
+			PanicLevel,
+			true,
+		},
+		{
+			PanicLevel,
+			ErrorLevel,
+			true,
+		},
+		{
+			PanicLevel,
+			DebugLevel,
+			true,
+		},
+		{
+			FatalLevel,
+			PanicLevel,
+			true,
+		},
+
 		// level is error
 		{
 			ErrorLevel,
 			FatalLevel,
 			false,
 		},
+		{
+			ErrorLevel,
+			PanicLevel,
+			false,
+		},
 		{
 			ErrorLevel,
 			ErrorLevel,
@@ -169,6 +215,23 @@ func TestIsLevelEnabled(t *testing.T) {
 			true,
 		},
 
+		// level is trace
+		{
+			TraceLevel,
+			DebugLevel,
+			false,
+		},
+		{
+			TraceLevel,
+			TraceLevel,
+			true,
+		},
+		{
+			DebugLevel,
+			TraceLevel,
+			true,
+		},
+
 		// level is debug
 		{
 			DebugLevel,
@@ -238,13 +301,23 @@ func TestParseLevelString(t *testing.T) {
 		},
 		{
 			"trace",
+			TraceLevel,
+			true,
+		},
+		{
+			"TRACE",
+			TraceLevel,
+			true,
+		},
+		{
+			"audit",
 			invalidLevel,
 			false,
 		},
 		{
 			"panic",
-			invalidLevel,
-			false,
+			PanicLevel,
+			true,
 		},
 		{
 			"",
@@ -286,6 +359,18 @@ func TestLevel_String(t *testing.T) {
 			DebugLevel,
 			"debug",
 		},
+		{
+			PanicLevel,
+			"panic",
+		},
+		{
+			TraceLevel,
+			"trace",
+		},
+		{
+			invalidLevel,
+			"unknown_0",
+		},
 	}
 	for _, v := range tests {
 		str := v.level.String()
@@ -295,3 +380,167 @@ func TestLevel_String(t *testing.T) {
 		}
 	}
 }
+
+// setLevelRegistry replaces the registry of the custom levels, the tests that register the levels restore it by
+//
+//	defer setLevelRegistry(getLevelRegistry())
+func setLevelRegistry(r *levelRegistry) {
+	_levelRegistryMu.Lock()
+	defer _levelRegistryMu.Unlock()
+	atomic.StorePointer(&_levelRegistryPtr, unsafe.Pointer(r))
+}
+
+func TestRegisterLevel(t *testing.T) {
+	defer setLevelRegistry(getLevelRegistry())
+
+	noticeLevel, err := RegisterLevel("Notice", 450, ColorCyan)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if noticeLevel < _firstCustomLevel {
+		t.Errorf("have:%d, want:>=%d", noticeLevel, _firstCustomLevel)
+	}
+	if !isValidLevel(noticeLevel) || noticeLevel.String() != "notice" || noticeLevel.Severity() != 450 || noticeLevel.Color() != ColorCyan {
+		t.Errorf("not expected level: %d, %s, %d, %d", noticeLevel, noticeLevel, noticeLevel.Severity(), noticeLevel.Color())
+	}
+	if level, ok := parseLevelString("NOTICE"); level != noticeLevel || !ok {
+		t.Errorf("have:(%d, %t), want:(%d, true)", level, ok, noticeLevel)
+	}
+	if !isLevelEnabled(noticeLevel, InfoLevel) || isLevelEnabled(noticeLevel, WarnLevel) || !isLevelEnabled(WarnLevel, noticeLevel) {
+		t.Error("not expected order")
+	}
+
+	for _, v := range []struct {
+		name     string
+		severity int
+	}{
+		{"notice", 450},
+		{"debug", 450},
+		{"", 450},
+		{"notice2", 0},
+	} {
+		if _, err := RegisterLevel(v.name, v.severity, NoColor); err == nil {
+			t.Errorf("name:%s, severity:%d, want error", v.name, v.severity)
+		}
+	}
+
+	var buf bytes.Buffer
+	lg := New(
+		WithOutput(&buf),
+		WithFormatter(NewTextFormatter(WithoutFields(TimeField|TraceIdField|LocationField), WithLevelColor())),
+	)
+	if err := lg.SetLevelString("notice"); err != nil {
+		t.Error(err.Error())
+	}
+	lg.Output(0, noticeLevel, "notice")
+	lg.Info("info")
+	lg.Warn("warn")
+	want := "level=\x1b[36mnotice\x1b[0m, msg=notice\n" +
+		"level=\x1b[33mwarning\x1b[0m, msg=warn\n"
+	if have := buf.String(); have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		text  string
+		level Level
+		ok    bool
+	}{
+		{"warning", WarnLevel, true},
+		{"Warn", WarnLevel, true},
+		{"err", ErrorLevel, true},
+		{"ERROR", ErrorLevel, true},
+		{"trace", TraceLevel, true},
+		{"panic", PanicLevel, true},
+		{"4", InfoLevel, true},
+		{"7", TraceLevel, true},
+		{"0", invalidLevel, false},
+		{"100", invalidLevel, false},
+		{"-1", invalidLevel, false},
+		{"", invalidLevel, false},
+		{"verbose", invalidLevel, false},
+	}
+	for _, v := range tests {
+		level, err := ParseLevel(v.text)
+		if level != v.level || (err == nil) != v.ok {
+			t.Errorf("text:%q, have:(%d, %v), want:(%d, %t)", v.text, level, err, v.level, v.ok)
+		}
+	}
+}
+
+func TestLevel_MarshalJSON(t *testing.T) {
+	type config struct {
+		Level  Level            `json:"level"`
+		Levels map[Level]string `json:"levels,omitempty"`
+	}
+	data, err := json.Marshal(config{Level: WarnLevel, Levels: map[Level]string{ErrorLevel: "e"}})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if have, want := string(data), `{"level":"warning","levels":{"error":"e"}}`; have != want {
+		t.Errorf("have:%s, want:%s", have, want)
+	}
+	// the zero Level
+	data, err = json.Marshal(config{})
+	if have, want := string(data), `{"level":""}`; err != nil || have != want {
+		t.Errorf("have:(%s, %v), want:%s", have, err, want)
+	}
+	if _, err := json.Marshal(config{Level: 99}); err == nil {
+		t.Error("want error for invalid level")
+	}
+	level := InfoLevel
+	if err := level.UnmarshalText([]byte{}); err != nil || level != invalidLevel {
+		t.Errorf("have:(%d, %v), want:(0, nil)", level, err)
+	}
+	if err := level.Set(""); err == nil {
+		t.Error("want error for the empty flag")
+	}
+
+	for _, v := range []struct {
+		data  string
+		level Level
+		ok    bool
+	}{
+		{`{"level":"debug"}`, DebugLevel, true},
+		{`{"level":"warn"}`, WarnLevel, true},
+		{`{"level":2}`, ErrorLevel, true},
+		{`{"level":"2"}`, ErrorLevel, true},
+		{`{"level":null}`, invalidLevel, true},
+		{`{"level":""}`, invalidLevel, true},
+		{`{"level":"unknown"}`, InfoLevel, false},
+		{`{"level":99}`, InfoLevel, false},
+	} {
+		c := config{Level: InfoLevel}
+		err := json.Unmarshal([]byte(v.data), &c)
+		if c.Level != v.level || (err == nil) != v.ok {
+			t.Errorf("data:%s, have:(%v, %v), want:(%v, %t)", v.data, c.Level, err, v.level, v.ok)
+		}
+	}
+}
+
+func TestLevel_Flag(t *testing.T) {
+	level := InfoLevel
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(&level, "level", "log level")
+	if err := fs.Parse([]string{"-level", "trace"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if level != TraceLevel {
+		t.Errorf("have:%v, want:%v", level, TraceLevel)
+	}
+	if have := fs.Lookup("level").Value.(flag.Getter).Get(); have != TraceLevel {
+		t.Errorf("have:%v, want:%v", have, TraceLevel)
+	}
+	if err := fs.Parse([]string{"-level", "verbose"}); err == nil {
+		t.Error("want error")
+	}
+	if level != TraceLevel {
+		t.Errorf("have:%v, want:%v", level, TraceLevel)
+	}
+}
@@ -0,0 +1,147 @@
+package log
+
+import (
+	"io"
+	"sort"
+)
+
+// LevelWriter is an io.Writer that also accepts the level of the entry being written.
+// If the logger output implements LevelWriter, the logger calls WriteLevel instead of Write.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, p []byte) (n int, err error)
+}
+
+func writeLevel(w io.Writer, level Level, p []byte) (n int, err error) {
+	if lw, ok := w.(LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.Write(p)
+}
+
+// LevelRouter returns a LevelWriter that routes the entries by level.
+//
+// An entry is written to levelOutputs[level] where level is the highest level in levelOutputs
+// that the level of the entry reaches, for example, with levelOutputs
+//
+//	{ErrorLevel: w1, FatalLevel: w2}
+//
+// the FatalLevel entries are written to w2, the ErrorLevel entries are written to w1,
+// and the others are written to output.
+//
+//	NOTE: output and all writers of levelOutputs must be thread-safe, see ConcurrentWriter.
+func LevelRouter(output io.Writer, levelOutputs map[Level]io.Writer) io.Writer {
+	if output == nil {
+		return nil
+	}
+	var routes *levelRoutes
+	for level, w := range levelOutputs {
+		routes = routes.withOutput(level, w)
+	}
+	return &levelRouter{
+		output: output,
+		routes: routes,
+	}
+}
+
+type levelRouter struct {
+	output io.Writer
+	routes *levelRoutes
+}
+
+func (r *levelRouter) Write(p []byte) (n int, err error) {
+	return r.output.Write(p)
+}
+
+func (r *levelRouter) WriteLevel(level Level, p []byte) (n int, err error) {
+	_, output := r.routes.lookup(level, nil, r.output)
+	return writeLevel(output, level, p)
+}
+
+// WithLevelOutput sets the output of the entries with level or higher level,
+// see LevelRouter for how the output is chosen when there are multiple level outputs.
+//
+//	NOTE: output must be thread-safe, see ConcurrentWriter.
+func WithLevelOutput(level Level, output io.Writer) Option {
+	return func(o *options) {
+		o.levelRoutes = o.levelRoutes.withOutput(level, output)
+	}
+}
+
+// WithLevelFormatter sets the formatter of the entries with level or higher level,
+// it is chosen the same way as WithLevelOutput.
+func WithLevelFormatter(level Level, formatter Formatter) Option {
+	return func(o *options) {
+		o.levelRoutes = o.levelRoutes.withFormatter(level, formatter)
+	}
+}
+
+type levelRoute struct {
+	level     Level
+	output    io.Writer
+	formatter Formatter
+}
+
+// levelRoutes is immutable, the routes are sorted from the highest level to the lowest level.
+type levelRoutes struct {
+	routes []levelRoute
+}
+
+func (r *levelRoutes) withOutput(level Level, output io.Writer) *levelRoutes {
+	if !isValidLevel(level) || output == nil {
+		return r
+	}
+	return r.with(level, func(route *levelRoute) { route.output = output })
+}
+
+func (r *levelRoutes) withFormatter(level Level, formatter Formatter) *levelRoutes {
+	if !isValidLevel(level) || formatter == nil {
+		return r
+	}
+	return r.with(level, func(route *levelRoute) { route.formatter = formatter })
+}
+
+func (r *levelRoutes) with(level Level, set func(*levelRoute)) *levelRoutes {
+	var routes []levelRoute
+	if r != nil {
+		routes = make([]levelRoute, len(r.routes), len(r.routes)+1)
+		copy(routes, r.routes)
+	}
+	for i := range routes {
+		if routes[i].level == level {
+			set(&routes[i])
+			return &levelRoutes{routes: routes}
+		}
+	}
+	route := levelRoute{level: level}
+	set(&route)
+	routes = append(routes, route)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return !isLevelEnabled(routes[j].level, routes[i].level)
+	})
+	return &levelRoutes{routes: routes}
+}
+
+// lookup returns the formatter and output for the level, formatter and output are the defaults.
+func (r *levelRoutes) lookup(level Level, formatter Formatter, output io.Writer) (Formatter, io.Writer) {
+	if r == nil {
+		return formatter, output
+	}
+	var foundFormatter, foundOutput bool
+	for i := range r.routes {
+		route := &r.routes[i]
+		if !isLevelEnabled(level, route.level) {
+			continue
+		}
+		if !foundFormatter && route.formatter != nil {
+			formatter, foundFormatter = route.formatter, true
+		}
+		if !foundOutput && route.output != nil {
+			output, foundOutput = route.output, true
+		}
+		if foundFormatter && foundOutput {
+			break
+		}
+	}
+	return formatter, output
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestLevelRouter(t *testing.T) {
+	var output, errorOutput, fatalOutput bytes.Buffer
+	router := LevelRouter(&output, map[Level]io.Writer{
+		ErrorLevel: &errorOutput,
+		FatalLevel: &fatalOutput,
+	})
+	lg := _New([]Option{
+		WithOutput(router),
+		WithFormatter(testMessageFormatter{}),
+	})
+	lg.Fatal("fatal")
+	lg.Error("error")
+	lg.Warn("warn")
+	lg.Info("info")
+	lg.Debug("debug")
+
+	if have, want := fatalOutput.String(), "fatal\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have, want := errorOutput.String(), "error\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have, want := output.String(), "warn\ninfo\ndebug\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	// plain Write
+	router.Write([]byte("write\n"))
+	if have, want := output.String(), "warn\ninfo\ndebug\nwrite\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	if LevelRouter(nil, nil) != nil {
+		t.Error("want nil")
+	}
+}
+
+func TestWithLevelOutput(t *testing.T) {
+	var output, errorOutput bytes.Buffer
+	lg := _New([]Option{
+		WithOutput(&output),
+		WithFormatter(testMessageFormatter{}),
+		WithLevelOutput(ErrorLevel, &errorOutput),
+		WithLevelFormatter(WarnLevel, testLevelFormatter{}),
+		WithLevelOutput(invalidLevel, &output), // ignored
+	})
+	lg.Fatal("fatal")
+	lg.Error("error")
+	lg.Warn("warn")
+	lg.Info("info")
+
+	if have, want := errorOutput.String(), "fatal:fatal\nerror:error\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have, want := output.String(), "warning:warn\ninfo\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+}
+
+func TestLevelRoutes_Immutable(t *testing.T) {
+	var w1, w2 bytes.Buffer
+	r1 := (*levelRoutes)(nil).withOutput(ErrorLevel, &w1)
+	r2 := r1.withOutput(ErrorLevel, &w2)
+	if _, w := r1.lookup(ErrorLevel, nil, nil); w != &w1 {
+		t.Error("want w1")
+	}
+	if _, w := r2.lookup(ErrorLevel, nil, nil); w != &w2 {
+		t.Error("want w2")
+	}
+}
+
+type testLevelFormatter struct{}
+
+func (testLevelFormatter) Format(entry *Entry) ([]byte, error) {
+	return []byte(entry.Level.String() + ":" + entry.Message + "\n"), nil
+}
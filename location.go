@@ -9,6 +9,11 @@ import (
 
 func callerLocation(skip int) string {
 	pc, file, line, ok := runtime.Caller(skip + 1)
+	return formatLocation(pc, file, line, ok)
+}
+
+// formatLocation formats the result of runtime.Caller as function(file:line).
+func formatLocation(pc uintptr, file string, line int, ok bool) string {
 	if !ok {
 		return "???"
 	}
@@ -19,6 +24,49 @@ func callerLocation(skip int) string {
 	return trimFuncName(fn.Name()) + "(" + trimFileName(file) + ":" + strconv.Itoa(line) + ")"
 }
 
+// pcLocation returns the location of the program counter pc in the same format as callerLocation.
+func pcLocation(pc uintptr) string {
+	if pc == 0 {
+		return "???"
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.Function == "" {
+		return trimFileName(frame.File) + ":" + strconv.Itoa(frame.Line)
+	}
+	return trimFuncName(frame.Function) + "(" + trimFileName(frame.File) + ":" + strconv.Itoa(frame.Line) + ")"
+}
+
+// _maxStackDepth is the max number of the frames captured by callerStack.
+const _maxStackDepth = 64
+
+// callerStack returns the frames of the stack of the caller, each frame is formatted as callerLocation,
+// the innermost frame is the first one.
+func callerStack(skip int) []string {
+	var pcs [_maxStackDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	if n == 0 {
+		return nil
+	}
+	stack := make([]string, 0, n)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		switch frame.Function {
+		case "runtime.main", "runtime.goexit":
+		default:
+			if frame.Function == "" {
+				stack = append(stack, trimFileName(frame.File)+":"+strconv.Itoa(frame.Line))
+			} else {
+				stack = append(stack, trimFuncName(frame.Function)+"("+trimFileName(frame.File)+":"+strconv.Itoa(frame.Line)+")")
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
 func trimFuncName(name string) string {
 	return path.Base(name)
 }
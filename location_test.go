@@ -51,3 +51,26 @@ func TestTrimFileName(t *testing.T) {
 		}
 	}
 }
+
+func testCallerStack() []string {
+	return callerStack(0)
+}
+
+func TestCallerStack(t *testing.T) {
+	stack := testCallerStack()
+	if len(stack) < 2 {
+		t.Errorf("not expected stack: %v", stack)
+		return
+	}
+	if !strings.HasPrefix(stack[0], "log.testCallerStack(") || !strings.HasSuffix(stack[0], "/location_test.go:56)") {
+		t.Errorf("not expected frame: %s", stack[0])
+	}
+	if !strings.HasPrefix(stack[1], "log.TestCallerStack(") {
+		t.Errorf("not expected frame: %s", stack[1])
+	}
+	for _, frame := range stack {
+		if strings.HasPrefix(frame, "runtime.goexit(") {
+			t.Errorf("not expected frame: %s", frame)
+		}
+	}
+}
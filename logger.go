@@ -14,13 +14,19 @@ type Logger interface {
 	// Fatal logs a message at FatalLevel.
 	//
 	// Unlike other golang log libraries (for example, the golang standard log library),
-	// Fatal just logs a message and does not call os.Exit, so you need to explicitly call os.Exit if necessary.
+	// Fatal just logs a message and does not call os.Exit, so you need to explicitly call os.Exit if necessary,
+	// or create the logger with WithExitOnFatal.
 	//
 	// For fields, the following conditions must be satisfied
 	//  1. the len(fields) must be an even number, that is to say len(fields)%2==0
 	//  2. the even index element of fields must be non-empty string
 	Fatal(msg string, fields ...interface{})
 
+	// Panic logs a message at PanicLevel, flushes the logger (see Flush) and then panics with msg.
+	// It panics even if PanicLevel is not enabled.
+	// The requirements for fields can see the comments of Fatal.
+	Panic(msg string, fields ...interface{})
+
 	// Error logs a message at ErrorLevel.
 	// The requirements for fields can see the comments of Fatal.
 	Error(msg string, fields ...interface{})
@@ -37,10 +43,16 @@ type Logger interface {
 	// The requirements for fields can see the comments of Fatal.
 	Debug(msg string, fields ...interface{})
 
+	// Trace logs a message at TraceLevel.
+	// The requirements for fields can see the comments of Fatal.
+	Trace(msg string, fields ...interface{})
+
 	// Output logs a message at specified level.
 	//
 	// For level==FatalLevel, unlike other golang log libraries (for example, the golang standard log library),
-	// Output just logs a message and does not call os.Exit, so you need to explicitly call os.Exit if necessary.
+	// Output just logs a message and does not call os.Exit, so you need to explicitly call os.Exit if necessary,
+	// or create the logger with WithExitOnFatal.
+	// For level==PanicLevel, Output panics the same way as Panic.
 	//
 	// The requirements for fields can see the comments of Fatal.
 	Output(calldepth int, level Level, msg string, fields ...interface{})
@@ -69,6 +81,18 @@ type Formatter interface {
 	Format(*Entry) ([]byte, error)
 }
 
+// fieldListFormatter is implemented by the formatters that can format the Entry with nil Fields,
+// the logger does not build Entry.Fields for them, so the values of the typed fields are not boxed.
+type fieldListFormatter interface {
+	Formatter
+	formatsFieldList()
+}
+
+func needsFieldsMap(formatter Formatter) bool {
+	_, ok := formatter.(fieldListFormatter)
+	return !ok
+}
+
 type Entry struct {
 	Location string // function(file:line)
 	Time     time.Time
@@ -77,6 +101,52 @@ type Entry struct {
 	Message  string
 	Fields   map[string]interface{}
 	Buffer   *bytes.Buffer
+
+	// Stack contains the frames of the stack of the caller if it is enabled by WithStacktrace,
+	// each frame is formatted as Location and the innermost frame is the first one.
+	Stack []string
+
+	// FieldList contains the same fields as Fields in insertion order, each key appears once with its last value.
+	// The formatters should treat FieldList as read-only, it may be shared with the logger.
+	FieldList []Field
+}
+
+func (entry *Entry) hasField(key string) bool {
+	if entry.Fields != nil {
+		_, ok := entry.Fields[key]
+		return ok
+	}
+	return containsField(entry.FieldList, key)
+}
+
+// SetField sets the field by key, a new field is appended to the end of FieldList.
+// FieldList is copied before it is modified since it may be shared with the logger.
+func (entry *Entry) SetField(field Field) {
+	if field.Key == "" {
+		return
+	}
+	list := make([]Field, len(entry.FieldList), len(entry.FieldList)+1)
+	copy(list, entry.FieldList)
+	entry.FieldList = setField(list, field)
+	if entry.Fields != nil {
+		entry.Fields[field.Key] = field.Interface()
+	}
+}
+
+// DeleteField deletes the field by key, see SetField.
+func (entry *Entry) DeleteField(key string) {
+	for i := range entry.FieldList {
+		if entry.FieldList[i].Key != key {
+			continue
+		}
+		list := make([]Field, 0, len(entry.FieldList)-1)
+		list = append(list, entry.FieldList[:i]...)
+		entry.FieldList = append(list, entry.FieldList[i+1:]...)
+		break
+	}
+	if entry.Fields != nil {
+		delete(entry.Fields, key)
+	}
 }
 
 func New(opts ...Option) Logger { return _New(opts) }
@@ -91,7 +161,7 @@ type logger struct {
 	mu      sync.Mutex     // protects the following options field
 	options unsafe.Pointer // *options
 
-	fields map[string]interface{}
+	fields []Field // immutable, shared by the derived loggers
 }
 
 func (l *logger) getOptions() (opts *options) {
@@ -123,6 +193,20 @@ func (l *logger) SetOutput(output io.Writer) {
 	opts.SetOutput(output)
 	l.setOptions(&opts)
 }
+
+// AddHooks adds the hooks to the logger, see WithHooks.
+// The loggers derived from the logger before AddHooks is called do not have the hooks.
+func (l *logger) AddHooks(hooks ...Hook) {
+	if len(hooks) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	opts := *l.getOptions()
+	opts.AddHooks(hooks)
+	l.setOptions(&opts)
+}
 func (l *logger) SetLevel(level Level) error {
 	if !isValidLevel(level) {
 		return fmt.Errorf("invalid level: %d", level)
@@ -139,6 +223,10 @@ func (l *logger) SetLevelString(str string) error {
 	return nil
 }
 func (l *logger) setLevel(level Level) {
+	if a := l.getOptions().atomicLevel; a != nil {
+		a.SetLevel(level)
+		return
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -150,6 +238,9 @@ func (l *logger) setLevel(level Level) {
 func (l *logger) Fatal(msg string, fields ...interface{}) {
 	l.output(1, FatalLevel, msg, fields)
 }
+func (l *logger) Panic(msg string, fields ...interface{}) {
+	l.output(1, PanicLevel, msg, fields)
+}
 func (l *logger) Error(msg string, fields ...interface{}) {
 	l.output(1, ErrorLevel, msg, fields)
 }
@@ -162,6 +253,9 @@ func (l *logger) Info(msg string, fields ...interface{}) {
 func (l *logger) Debug(msg string, fields ...interface{}) {
 	l.output(1, DebugLevel, msg, fields)
 }
+func (l *logger) Trace(msg string, fields ...interface{}) {
+	l.output(1, TraceLevel, msg, fields)
+}
 
 func (l *logger) Output(calldepth int, level Level, msg string, fields ...interface{}) {
 	if !isValidLevel(level) {
@@ -175,58 +269,226 @@ func (l *logger) Output(calldepth int, level Level, msg string, fields ...interf
 
 func (l *logger) output(calldepth int, level Level, msg string, fields []interface{}) {
 	opts := l.getOptions()
-	if !isLevelEnabled(level, opts.level) {
+	l.outputInterfaces(opts, calldepth+1, level, msg, fields)
+	if level == FatalLevel || level == PanicLevel {
+		opts.terminate(calldepth+1, level, msg)
+	}
+}
+
+func (l *logger) outputInterfaces(opts *options, calldepth int, level Level, msg string, fields []interface{}) {
+	l.outputWith(opts, calldepth+1, 0, time.Time{}, "", level, msg, func(list []Field) ([]Field, error) {
+		return combineFields(list, fields)
+	})
+}
+
+func (l *logger) outputFields(calldepth int, level Level, msg string, fields []Field) {
+	opts := l.getOptions()
+	l.outputTypedFields(opts, calldepth+1, level, msg, fields)
+	if level == FatalLevel || level == PanicLevel {
+		opts.terminate(calldepth+1, level, msg)
+	}
+}
+
+func (l *logger) outputTypedFields(opts *options, calldepth int, level Level, msg string, fields []Field) {
+	l.outputWith(opts, calldepth+1, 0, time.Time{}, "", level, msg, func(list []Field) ([]Field, error) {
+		return combineTypedFields(list, fields)
+	})
+}
+
+// outputWith is the pipeline shared by the logging methods: it checks the level, samples the entry,
+// locates the caller, captures the stack, combines the fields of the logger and the entry by combine,
+// and then outputs the entry.
+//
+// The caller is located by calldepth, or by pc if calldepth is negative (the records of slog,
+// the stack is not captured for them). now and traceId default to the current time and the trace id of the logger.
+func (l *logger) outputWith(opts *options, calldepth int, pc uintptr, now time.Time, traceId string,
+	level Level, msg string, combine func(list []Field) ([]Field, error)) {
+	var location string
+	if calldepth < 0 {
+		if !opts.checkPC(pc, level) {
+			return
+		}
+	} else {
+		var ok bool
+		if location, ok = opts.checkCaller(calldepth+1, level); !ok {
+			return
+		}
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	l.outputPending(opts, now, false)
+	suppressed, ok := opts.sampler.sample(level, msg, now)
+	if !ok {
 		return
 	}
-	location := callerLocation(calldepth + 1)
+	if location == "" {
+		if calldepth < 0 {
+			location = pcLocation(pc)
+		} else {
+			location = callerLocation(calldepth + 1)
+		}
+	}
+	if suppressed > 0 {
+		l.outputSuppressed(opts, location, now, level, msg, suppressed)
+	}
+	var stack []string
+	if calldepth >= 0 && opts.isStackEnabled(level) {
+		stack = callerStack(calldepth + 1)
+	}
 
-	combinedFields, err := combineFields(l.fields, fields)
+	fieldList, err := combine(l.fields)
 	if err != nil {
 		fmt.Fprintf(ConcurrentStderr, "log: failed to combine fields, error=%v, location=%s\n", err, location)
 	}
+	if traceId == "" {
+		traceId = opts.traceId
+	}
+	l.outputEntry(opts, &Entry{
+		Location:  location,
+		Time:      now,
+		Level:     level,
+		TraceId:   traceId,
+		Message:   msg,
+		Stack:     stack,
+		FieldList: fieldList,
+	})
+}
+
+// outputSuppressed logs the number of the entries with level and msg suppressed by WithSampling.
+func (l *logger) outputSuppressed(opts *options, location string, now time.Time, level Level, msg string, suppressed uint64) {
+	fieldList := make([]Field, len(l.fields), len(l.fields)+1)
+	copy(fieldList, l.fields)
+	l.outputEntry(opts, &Entry{
+		Location:  location,
+		Time:      now,
+		Level:     level,
+		TraceId:   opts.traceId,
+		Message:   msg,
+		FieldList: setField(fieldList, Uint64(SampledField, suppressed)),
+	})
+}
+
+// outputPending logs the numbers of the suppressed entries that have not been reported, see sampler.pending.
+// The fields of the logger are not added since the suppressed entries may be logged by the other loggers.
+func (l *logger) outputPending(opts *options, now time.Time, all bool) {
+	for _, summary := range opts.sampler.pending(now, all) {
+		l.outputEntry(opts, &Entry{
+			Location:  "???",
+			Time:      now,
+			Level:     summary.level,
+			Message:   summary.msg,
+			FieldList: []Field{Uint64(SampledField, summary.suppressed)},
+		})
+	}
+}
+
+// outputEntry redacts the entry, fires the hooks, formats and writes the entry, entry.Fields is built from entry.FieldList if the formatter needs it.
+func (l *logger) outputEntry(opts *options, entry *Entry) {
+	opts.redactor.redact(entry)
+	if !opts.hooks.fire(entry) {
+		return
+	}
+	if opts.sinks != nil {
+		if opts.sinks.needsFieldsMap {
+			entry.Fields = fieldsMap(entry.FieldList)
+		}
+		opts.sinks.write(opts, entry)
+		return
+	}
 
 	pool := getBytesBufferPool()
 	buffer := pool.Get()
 	defer pool.Put(buffer)
 	buffer.Reset()
 
-	data, err := opts.formatter.Format(&Entry{
-		Location: location,
-		Time:     time.Now(),
-		Level:    level,
-		TraceId:  opts.traceId,
-		Message:  msg,
-		Fields:   combinedFields,
-		Buffer:   buffer,
-	})
+	formatter, output := opts.levelRoutes.lookup(entry.Level, opts.formatter, opts.output)
+	if needsFieldsMap(formatter) {
+		entry.Fields = fieldsMap(entry.FieldList)
+	}
+	entry.Buffer = buffer
+	data, err := formatter.Format(entry)
 	if err != nil {
-		fmt.Fprintf(ConcurrentStderr, "log: failed to format Entry, error=%v, location=%s\n", err, location)
+		fmt.Fprintf(ConcurrentStderr, "log: failed to format Entry, error=%v, location=%s\n", err, entry.Location)
 		return
 	}
-	if _, err = opts.output.Write(data); err != nil {
-		fmt.Fprintf(ConcurrentStderr, "log: failed to write to log, error=%v, location=%s\n", err, location)
+	if err = opts.write(output, entry.Level, data); err != nil {
+		fmt.Fprintf(ConcurrentStderr, "log: failed to write to log, error=%v, location=%s\n", err, entry.Location)
 		return
 	}
 }
 
+// Flush logs the numbers of the entries suppressed by WithSampling that have not been reported,
+// blocks until all the entries buffered by WithAsync have been written,
+// and then flushes the outputs that implement Flusher.
+func (l *logger) Flush() error {
+	opts := l.getOptions()
+	l.outputPending(opts, time.Now(), true)
+	return opts.flush()
+}
+
+// Close logs the numbers of the entries suppressed by WithSampling that have not been reported,
+// writes all the entries buffered by WithAsync and stops the background goroutine,
+// the entries logged after Close are written synchronously.
+func (l *logger) Close() error {
+	opts := l.getOptions()
+	l.outputPending(opts, time.Now(), true)
+	if async := opts.async; async != nil {
+		return async.Close()
+	}
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the WithAsync buffer was full.
+func (l *logger) Dropped() uint64 {
+	if async := l.getOptions().async; async != nil {
+		return async.Dropped()
+	}
+	return 0
+}
+
+// setExitOnFatal makes the logger call exitFunc(1) after a FatalLevel entry is logged and the logger is flushed
+// (see WithExitOnFatal), the logger does not exit if exitFunc is nil.
+// The loggers derived from the logger before setExitOnFatal is called are not affected.
+func (l *logger) setExitOnFatal(exitFunc func(code int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	opts := *l.getOptions()
+	opts.exitFunc = nil
+	if exitFunc != nil {
+		WithExitOnFatal(exitFunc)(&opts)
+	}
+	l.setOptions(&opts)
+}
+
+// setAsync replaces the asynchronous writer of the logger with a new one (see WithAsync),
+// the logger writes synchronously if bufferSize <= 0. The previous asynchronous writer is closed
+// after the replacement, so the entries buffered by it are not lost.
+// The loggers derived from the logger before setAsync is called are not affected.
+func (l *logger) setAsync(bufferSize int, policy OverflowPolicy) error {
+	l.mu.Lock()
+	opts := *l.getOptions()
+	old := opts.async
+	opts.async = nil
+	WithAsync(bufferSize, policy)(&opts)
+	l.setOptions(&opts)
+	l.mu.Unlock()
+
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
 func (l *logger) WithField(key string, value interface{}) Logger {
 	if key == "" {
 		return l
 	}
-	if len(l.fields) == 0 {
-		nl := &logger{
-			fields: map[string]interface{}{key: value},
-		}
-		nl.setOptions(l.getOptions())
-		return nl
-	}
-	m := make(map[string]interface{}, len(l.fields)+1)
-	for k, v := range l.fields {
-		m[k] = v
-	}
-	m[key] = value
+	list := make([]Field, len(l.fields), len(l.fields)+1)
+	copy(list, l.fields)
 	nl := &logger{
-		fields: m,
+		fields: setField(list, Field{Key: key, Value: value}),
 	}
 	nl.setOptions(l.getOptions())
 	return nl
@@ -236,12 +498,12 @@ func (l *logger) WithFields(fields ...interface{}) Logger {
 	if len(fields) == 0 {
 		return l
 	}
-	m, err := combineFields(l.fields, fields)
+	list, err := combineFields(l.fields, fields)
 	if err != nil {
 		fmt.Fprintf(ConcurrentStderr, "log: failed to combine fields, error=%v, location=%s\n", err, callerLocation(1))
 	}
 	nl := &logger{
-		fields: m,
+		fields: list,
 	}
 	nl.setOptions(l.getOptions())
 	return nl
@@ -0,0 +1,27 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func BenchmarkLogger_Info(b *testing.B) {
+	lg := New(WithOutput(ioutil.Discard), WithFormatter(JsonFormatter))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lg.Info("msg", "string", "value", "int", 123456789, "duration", time.Second)
+		}
+	})
+}
+
+func BenchmarkLogger_InfoF(b *testing.B) {
+	lg := NewFieldLogger(WithOutput(ioutil.Discard), WithFormatter(JsonFormatter))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lg.InfoF("msg", String("string", "value"), Int("int", 123456789), Duration("duration", time.Second))
+		}
+	})
+}
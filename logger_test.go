@@ -1,6 +1,13 @@
 package log
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestLogger_New(t *testing.T) {
 	lg1 := _New([]Option{
@@ -56,3 +63,159 @@ func TestLogger_SetOptions_GetOptions(t *testing.T) {
 		}
 	}
 }
+
+type testEntryFormatter struct {
+	entry *Entry
+}
+
+func (f *testEntryFormatter) Format(entry *Entry) ([]byte, error) {
+	*f.entry = *entry
+	return nil, nil
+}
+
+func TestLogger_FieldList(t *testing.T) {
+	var entry Entry
+	lg := New(WithFormatter(&testEntryFormatter{entry: &entry}))
+	lg = lg.WithField("c", 1).WithFields("b", 2, "a", 3).WithField("c", 4)
+	lg2 := lg.WithField("d", 5)
+
+	lg.Info("msg", "e", 6, "b", 7)
+	want := []Field{{Key: "c", Value: 4}, {Key: "b", Value: 7}, {Key: "a", Value: 3}, {Key: "e", Value: 6}}
+	if !reflect.DeepEqual(entry.FieldList, want) {
+		t.Errorf("have:%v, want:%v", entry.FieldList, want)
+		return
+	}
+	if want := fieldsMap(want); !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("have:%v, want:%v", entry.Fields, want)
+		return
+	}
+
+	// the derived logger is not affected
+	lg2.Info("msg")
+	want = []Field{{Key: "c", Value: 4}, {Key: "b", Value: 2}, {Key: "a", Value: 3}, {Key: "d", Value: 5}}
+	if !reflect.DeepEqual(entry.FieldList, want) {
+		t.Errorf("have:%v, want:%v", entry.FieldList, want)
+		return
+	}
+}
+
+func TestLogger_Stacktrace(t *testing.T) {
+	var entry Entry
+	lg := New(WithFormatter(&testEntryFormatter{entry: &entry}), WithStacktrace(ErrorLevel))
+
+	lg.Warn("msg")
+	if entry.Stack != nil {
+		t.Errorf("have:%v, want:nil", entry.Stack)
+	}
+	lg.Error("msg")
+	if len(entry.Stack) == 0 || !strings.HasPrefix(entry.Stack[0], "log.TestLogger_Stacktrace(") {
+		t.Errorf("not expected stack: %v", entry.Stack)
+	}
+	if entry.Stack[0] != entry.Location {
+		t.Errorf("have:%s, want:%s", entry.Stack[0], entry.Location)
+	}
+	lg.(FieldLogger).FatalF("msg")
+	if len(entry.Stack) == 0 || !strings.HasPrefix(entry.Stack[0], "log.TestLogger_Stacktrace(") {
+		t.Errorf("not expected stack: %v", entry.Stack)
+	}
+}
+
+type testFlushWriter struct {
+	bytes.Buffer
+	flushed int
+}
+
+func (w *testFlushWriter) Flush() error {
+	w.flushed++
+	return nil
+}
+
+func TestLogger_ExitOnFatal(t *testing.T) {
+	var (
+		w    testFlushWriter
+		code = -1
+	)
+	lg := New(
+		WithOutput(&w),
+		WithFormatter(testMessageFormatter{}),
+		WithAsync(16, OverflowBlock),
+		WithExitOnFatal(func(c int) {
+			if have := w.String(); !strings.HasSuffix(have, "fatal\n") {
+				t.Errorf("not flushed before exit, have:%q", have)
+			}
+			code = c
+		}),
+	)
+	defer lg.(io.Closer).Close()
+
+	lg.Error("error")
+	if code != -1 || w.flushed != 0 {
+		t.Errorf("have:(%d, %d), want:(-1, 0)", code, w.flushed)
+	}
+	lg.Fatal("fatal")
+	if code != 1 || w.flushed != 1 {
+		t.Errorf("have:(%d, %d), want:(1, 1)", code, w.flushed)
+	}
+	lg.(FieldLogger).FatalF("fatal")
+	if w.flushed != 2 {
+		t.Errorf("have:%d, want:2", w.flushed)
+	}
+
+	// without WithExitOnFatal
+	w.flushed = 0
+	New(WithOutput(&w), WithFormatter(testMessageFormatter{})).Fatal("fatal")
+	if w.flushed != 0 {
+		t.Errorf("have:%d, want:0", w.flushed)
+	}
+}
+
+func TestLogger_Panic(t *testing.T) {
+	var w testFlushWriter
+	lg := New(WithOutput(&w), WithFormatter(testMessageFormatter{}), WithLevel(ErrorLevel))
+
+	testPanic := func(fn func(), want string) {
+		t.Helper()
+		defer func() {
+			if have := recover(); have != want {
+				t.Errorf("have:%v, want:%v", have, want)
+			}
+		}()
+		fn()
+	}
+	testPanic(func() { lg.Panic("panic", "k", "v") }, "panic")
+	testPanic(func() { lg.Output(0, PanicLevel, "output") }, "output")
+	testPanic(func() { lg.(FieldLogger).PanicF("panicF") }, "panicF")
+	testPanic(func() { PanicContext(NewContext(context.Background(), lg), "context") }, "context")
+	testPanic(func() { NoopLogger{}.Panic("noop") }, "noop")
+
+	if have, want := w.String(), "panic\noutput\npanicF\ncontext\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if w.flushed != 4 {
+		t.Errorf("have:%d, want:4", w.flushed)
+	}
+
+	// PanicLevel is not enabled
+	lg.SetLevel(FatalLevel)
+	testPanic(func() { lg.Panic("disabled") }, "disabled")
+	if strings.Contains(w.String(), "disabled") {
+		t.Errorf("not expected output: %q", w.String())
+	}
+}
+
+func TestLogger_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	lg := New(WithOutput(&buf), WithFormatter(testMessageFormatter{}))
+	lg.Trace("trace")
+	lg.(FieldLogger).TraceF("traceF")
+	if have := buf.String(); have != "" {
+		t.Errorf("have:%q, want empty", have)
+	}
+	lg.SetLevel(TraceLevel)
+	lg.Trace("trace")
+	lg.(FieldLogger).TraceF("traceF")
+	TraceContext(NewContext(context.Background(), lg), "context")
+	if have, want := buf.String(), "trace\ntraceF\ncontext\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+}
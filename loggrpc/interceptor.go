@@ -0,0 +1,260 @@
+// Package loggrpc provides the gRPC interceptors that build the request-scoped loggers the same way as log.Middleware
+// and propagate the trace ids the same way as log.Transport.
+//
+// It is a separate module so that the log package does not depend on gRPC.
+package loggrpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KeKe-Li/log"
+	"github.com/KeKe-Li/log/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TraceIdMetadataKey is the metadata key of the trace id, the metadata keys are lower case.
+var TraceIdMetadataKey = strings.ToLower(trace.TraceIdHeaderKey)
+
+const (
+	// AccessLogMessage is the message of the access log entries of the server interceptors.
+	AccessLogMessage = "grpc request"
+
+	// CallLogMessage is the message of the call log entries of the client interceptors.
+	CallLogMessage = "grpc call"
+)
+
+type Option func(*options)
+
+// WithLevel sets the level of the log entries of the calls succeeded or failed with the codes caused by the client,
+// the default is log.InfoLevel. The calls failed with the codes caused by the server
+// (Unknown, DeadlineExceeded, Unimplemented, Internal, Unavailable and DataLoss) are logged at log.ErrorLevel.
+func WithLevel(level log.Level) Option {
+	return func(o *options) {
+		if level.Severity() == 0 { // invalid level
+			return
+		}
+		o.level = level
+	}
+}
+
+// WithSkipMethods disables the log entries of the methods, the full method names are in the form
+// "/package.service/method", for example "/grpc.health.v1.Health/Check".
+// The calls still have the request-scoped logger and the trace id.
+func WithSkipMethods(methods ...string) Option {
+	return func(o *options) {
+		if len(methods) == 0 {
+			return
+		}
+		if o.skipMethods == nil {
+			o.skipMethods = make(map[string]struct{}, len(methods))
+		}
+		for _, m := range methods {
+			o.skipMethods[m] = struct{}{}
+		}
+	}
+}
+
+type options struct {
+	level       log.Level
+	skipMethods map[string]struct{}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{level: log.InfoLevel}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(o)
+	}
+	return o
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that builds a request-scoped logger from base.
+//
+// For each call, the trace id is taken from the incoming metadata (see TraceIdMetadataKey) or the context
+// (see trace.FromContext), or generated by trace.NewTraceId. It is sent back in the header metadata,
+// and the logger derived from base with the trace id is stored in the context of the handler, see log.FromContext.
+//
+// After the call is handled, a log entry is logged with the fields method, code, duration and error (if the call failed).
+func UnaryServerInterceptor(base log.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, lg := serverContext(ctx, base, func(md metadata.MD) error {
+			return grpc.SetHeader(ctx, md)
+		})
+		resp, err := handler(ctx, req)
+		o.log(lg, AccessLogMessage, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the same as UnaryServerInterceptor but for the streams,
+// the log entry is logged after the handler returns.
+func StreamServerInterceptor(base log.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, lg := serverContext(ss.Context(), base, ss.SetHeader)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		o.log(lg, AccessLogMessage, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// serverContext returns the context with the trace id and the request-scoped logger, see UnaryServerInterceptor.
+func serverContext(ctx context.Context, base log.Logger, setHeader func(metadata.MD) error) (context.Context, log.Logger) {
+	traceId := incomingTraceId(ctx)
+	if traceId == "" {
+		traceId = trace.NewTraceId()
+	}
+	setHeader(metadata.Pairs(TraceIdMetadataKey, traceId)) // fails only if the header has been sent
+	lg := log.WithTraceIdLogger(base, traceId)
+	return log.NewContext(trace.NewContext(ctx, traceId), lg), lg
+}
+
+func incomingTraceId(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TraceIdMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	traceId, _ := trace.FromContext(ctx)
+	return traceId
+}
+
+// serverStream overrides the context of grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that propagates the trace id in the outgoing metadata.
+//
+// The trace id is taken from the context by trace.FromContext, or the TraceId of the logger of the context
+// (see log.FromContext), it is not set if the outgoing metadata already has it.
+// If the context has a logger, each call is logged with the fields method, code, duration and error (if the call failed).
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx = outgoingContext(ctx)
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if lg, ok := log.FromContext(ctx); ok {
+			o.log(lg, CallLogMessage, method, start, err)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor is the same as UnaryClientInterceptor but for the streams,
+// the call is logged when the stream fails to be created or RecvMsg reports the end of the stream.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx = outgoingContext(ctx)
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		lg, ok := log.FromContext(ctx)
+		if !ok || o.skip(method) {
+			return cs, err
+		}
+		if err != nil {
+			o.log(lg, CallLogMessage, method, start, err)
+			return cs, err
+		}
+		return &clientStream{
+			ClientStream:  cs,
+			serverStreams: desc.ServerStreams,
+			finish: func(err error) {
+				o.log(lg, CallLogMessage, method, start, err)
+			},
+		}, nil
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(TraceIdMetadataKey)) > 0 {
+		return ctx
+	}
+	traceId, ok := trace.FromContext(ctx)
+	if !ok {
+		if lg, ok := log.FromContext(ctx); ok {
+			if tracer, ok := lg.(trace.Tracer); ok {
+				traceId = tracer.TraceId()
+			}
+		}
+	}
+	if traceId == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, TraceIdMetadataKey, traceId)
+}
+
+// clientStream calls finish once when RecvMsg reports the end of the stream.
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	once          sync.Once
+	finish        func(err error)
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.once.Do(func() { s.finish(nil) })
+	case err != nil:
+		s.once.Do(func() { s.finish(err) })
+	case !s.serverStreams: // the single response of the client streaming
+		s.once.Do(func() { s.finish(nil) })
+	}
+	return err
+}
+
+func (o *options) skip(method string) bool {
+	_, ok := o.skipMethods[method]
+	return ok
+}
+
+func (o *options) log(lg log.Logger, msg, method string, start time.Time, err error) {
+	if o.skip(method) {
+		return
+	}
+	code := status.Code(err)
+	level := o.level
+	if isServerError(code) {
+		level = log.ErrorLevel
+	}
+	fields := []interface{}{
+		"method", method,
+		"code", code.String(),
+		"duration", time.Since(start),
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	lg.Output(0, level, msg, fields...)
+}
+
+// isServerError reports whether the code is caused by the server rather than the client.
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
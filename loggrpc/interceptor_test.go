@@ -0,0 +1,205 @@
+package loggrpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KeKe-Li/log"
+	"github.com/KeKe-Li/log/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testEntries struct {
+	mu      sync.Mutex
+	entries []log.Entry
+}
+
+func (e *testEntries) Format(entry *log.Entry) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entries = append(e.entries, *entry)
+	return nil, nil
+}
+
+// wait waits for the entry with msg and returns it.
+func (e *testEntries) wait(t *testing.T, msg string) log.Entry {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		e.mu.Lock()
+		for i, entry := range e.entries {
+			if entry.Message == msg {
+				e.entries = append(e.entries[:i], e.entries[i+1:]...)
+				e.mu.Unlock()
+				return entry
+			}
+		}
+		e.mu.Unlock()
+	}
+	t.Fatalf("timeout waiting for %q", msg)
+	return log.Entry{}
+}
+
+func (e *testEntries) len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.entries)
+}
+
+func fieldValue(entry log.Entry, key string) interface{} {
+	for _, f := range entry.FieldList {
+		if f.Key == key {
+			return f.Interface()
+		}
+	}
+	return nil
+}
+
+func newTestClient(t *testing.T, serverLogger log.Logger, checkContext func(ctx context.Context)) healthpb.HealthClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(serverLogger, WithSkipMethods("/grpc.health.v1.Health/List")),
+			func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				checkContext(ctx)
+				return handler(ctx, req)
+			}),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(serverLogger),
+			func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				checkContext(ss.Context())
+				return handler(srv, ss)
+			}),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("ok", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(WithLevel(log.DebugLevel))),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	var (
+		serverEntries testEntries
+		clientEntries testEntries
+	)
+	serverLogger := log.New(log.WithFormatter(&serverEntries))
+	clientLogger := log.New(log.WithFormatter(&clientEntries), log.WithTraceId("123"))
+	client := newTestClient(t, serverLogger, func(ctx context.Context) {
+		lg, ok := log.FromContext(ctx)
+		traceId, _ := trace.FromContext(ctx)
+		if !ok || lg.(trace.Tracer).TraceId() != traceId {
+			t.Errorf("have:(%v, %q), want the request-scoped logger", ok, traceId)
+		}
+	})
+
+	// trace id from the context logger
+	var header metadata.MD
+	ctx := log.NewContext(context.Background(), clientLogger)
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "ok"}, grpc.Header(&header)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if have := header.Get(TraceIdMetadataKey); len(have) != 1 || have[0] != "123" {
+		t.Errorf("have:%v, want:[123]", have)
+	}
+	entry := serverEntries.wait(t, AccessLogMessage)
+	if entry.TraceId != "123" || entry.Level != log.InfoLevel {
+		t.Errorf("have:(%q, %v), want:(123, info)", entry.TraceId, entry.Level)
+	}
+	if have := fieldValue(entry, "method"); have != "/grpc.health.v1.Health/Check" {
+		t.Errorf("have:%v, want:/grpc.health.v1.Health/Check", have)
+	}
+	if have := fieldValue(entry, "code"); have != codes.OK.String() {
+		t.Errorf("have:%v, want:%v", have, codes.OK)
+	}
+	if _, ok := fieldValue(entry, "duration").(time.Duration); !ok {
+		t.Errorf("have:%v, want time.Duration", fieldValue(entry, "duration"))
+	}
+	entry = clientEntries.wait(t, CallLogMessage)
+	if entry.TraceId != "123" || fieldValue(entry, "code") != codes.OK.String() {
+		t.Errorf("have:(%q, %v), want:(123, OK)", entry.TraceId, entry.FieldList)
+	}
+
+	// the client error, trace id from the outgoing metadata
+	ctx = metadata.AppendToOutgoingContext(ctx, TraceIdMetadataKey, "456")
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("have:%v, want:%v", err, codes.NotFound)
+	}
+	entry = serverEntries.wait(t, AccessLogMessage)
+	if entry.TraceId != "456" || entry.Level != log.InfoLevel || fieldValue(entry, "code") != codes.NotFound.String() || fieldValue(entry, "error") == nil {
+		t.Errorf("have:(%q, %v, %v), want:(456, info, NotFound)", entry.TraceId, entry.Level, entry.FieldList)
+	}
+	clientEntries.wait(t, CallLogMessage)
+
+	// generated trace id, skipped method, no client logger
+	header = nil
+	if _, err = client.List(context.Background(), &healthpb.HealthListRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if have := header.Get(TraceIdMetadataKey); len(have) != 1 || have[0] == "" {
+		t.Errorf("have:%v, want the generated trace id", have)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if have := serverEntries.len() + clientEntries.len(); have != 0 {
+		t.Errorf("have:%d entries, want no entry", have)
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	var (
+		serverEntries testEntries
+		clientEntries testEntries
+	)
+	serverLogger := log.New(log.WithFormatter(&serverEntries))
+	clientLogger := log.New(log.WithFormatter(&clientEntries))
+	client := newTestClient(t, serverLogger, func(ctx context.Context) {
+		if traceId, _ := trace.FromContext(ctx); traceId != "789" {
+			t.Errorf("have:%q, want:789", traceId)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(trace.NewContext(log.NewContext(context.Background(), clientLogger), "789"))
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "ok"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	resp, err := stream.Recv()
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("have:(%v, %v), want:SERVING", resp, err)
+	}
+	cancel()
+	if _, err = stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("have:%v, want:%v", err, codes.Canceled)
+	}
+
+	entry := clientEntries.wait(t, CallLogMessage)
+	if entry.Level != log.DebugLevel || fieldValue(entry, "method") != "/grpc.health.v1.Health/Watch" || fieldValue(entry, "code") != codes.Canceled.String() {
+		t.Errorf("have:(%v, %v), want:(debug, Watch, Canceled)", entry.Level, entry.FieldList)
+	}
+	entry = serverEntries.wait(t, AccessLogMessage)
+	if entry.TraceId != "789" || fieldValue(entry, "method") != "/grpc.health.v1.Health/Watch" {
+		t.Errorf("have:(%q, %v), want:(789, Watch)", entry.TraceId, entry.FieldList)
+	}
+}
@@ -0,0 +1,183 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/KeKe-Li/log/trace"
+)
+
+type MiddlewareOption func(*middlewareOptions)
+
+// WithSkipPaths disables the access log of the requests with the paths, for example "/healthz".
+// The requests still have the request-scoped logger.
+func WithSkipPaths(paths ...string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		if len(paths) == 0 {
+			return
+		}
+		if o.skipPaths == nil {
+			o.skipPaths = make(map[string]struct{}, len(paths))
+		}
+		for _, p := range paths {
+			o.skipPaths[p] = struct{}{}
+		}
+	}
+}
+
+// WithSkipFunc disables the access log of the requests for which skip returns true.
+func WithSkipFunc(skip func(req *http.Request) bool) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		if skip == nil {
+			return
+		}
+		o.skipFuncs = append(o.skipFuncs, skip)
+	}
+}
+
+// WithAccessLevel sets the level of the access log of the requests with the status below 500,
+// the default is InfoLevel, the requests with the status 500 and above are logged at ErrorLevel.
+func WithAccessLevel(level Level) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		if !isValidLevel(level) {
+			return
+		}
+		o.level = level
+	}
+}
+
+type middlewareOptions struct {
+	skipPaths map[string]struct{}
+	skipFuncs []func(req *http.Request) bool
+	level     Level
+}
+
+func (o *middlewareOptions) skip(req *http.Request) bool {
+	if _, ok := o.skipPaths[req.URL.Path]; ok {
+		return true
+	}
+	for _, skip := range o.skipFuncs {
+		if skip(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessLogMessage is the message of the access log entries of Middleware.
+const AccessLogMessage = "http request"
+
+// Middleware returns an HTTP middleware that builds a request-scoped logger from base, for example
+//
+//	handler = log.Middleware(lg, log.WithSkipPaths("/healthz"))(handler)
+//
+// For each request, the trace id is taken from the request (see trace.FromRequest) or generated by trace.NewTraceId,
+// it is set as the response header X-Request-Id and the trace id of the request context (see trace.NewContext).
+// The logger derived from base with the trace id is stored in the request context, see FromRequest.
+//
+// After the request is served, an access log entry is logged with the fields
+// method, path, status, bytes (the size of the response body), latency and remote_addr.
+// If the handler panics, the request is logged at ErrorLevel with the status 500 and the field panic,
+// and then the handler panics again.
+func Middleware(base Logger, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	o := middlewareOptions{level: InfoLevel}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			traceId, ok := trace.FromRequest(req)
+			if !ok {
+				traceId = trace.NewTraceId()
+			}
+			w.Header().Set(trace.TraceIdHeaderKey, traceId)
+			lg := WithTraceIdLogger(base, traceId)
+			req = NewRequest(req.WithContext(trace.NewContext(req.Context(), traceId)), lg)
+
+			if o.skip(req) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				status := rw.status
+				if p != nil {
+					status = http.StatusInternalServerError
+				} else if status == 0 {
+					status = http.StatusOK
+				}
+				level := o.level
+				if status >= http.StatusInternalServerError {
+					level = ErrorLevel
+				}
+				fields := []interface{}{
+					"method", req.Method,
+					"path", req.URL.Path,
+					"status", status,
+					"bytes", rw.bytes,
+					"latency", time.Since(start),
+					"remote_addr", req.RemoteAddr,
+				}
+				if p != nil {
+					fields = append(fields, "panic", p)
+				}
+				lg.Output(0, level, AccessLogMessage, fields...)
+				if p != nil {
+					panic(p) // for the recovery of http.Server or the outer middlewares
+				}
+			}()
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// responseWriter records the status and the size of the response body.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher, it does nothing if the underlying http.ResponseWriter does not implement it.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("the http.ResponseWriter does not implement http.Hijacker")
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,147 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KeKe-Li/log/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	var entries []Entry
+	lg := New(WithFormatter(testFuncFormatter(func(entry *Entry) {
+		entries = append(entries, *entry)
+	})))
+	handler := Middleware(lg, WithSkipPaths("/healthz"))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rlg, ok := FromRequest(req)
+		if !ok {
+			t.Error("want the request-scoped logger")
+			return
+		}
+		traceId, _ := trace.FromContext(req.Context())
+		if have := rlg.(*logger).TraceId(); have != traceId || have == "" {
+			t.Errorf("have:%q, want:%q", have, traceId)
+		}
+		rlg.Info("handler")
+		if req.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		w.Write([]byte("hello"))
+	}))
+
+	// trace id from the request header
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	req.Header.Set(trace.TraceIdHeaderKey, "123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if have := rec.Header().Get(trace.TraceIdHeaderKey); have != "123" {
+		t.Errorf("have:%q, want:123", have)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("have:%d entries, want:2", len(entries))
+	}
+	if entries[0].Message != "handler" || entries[0].TraceId != "123" {
+		t.Errorf("have:(%q, %q), want:(handler, 123)", entries[0].Message, entries[0].TraceId)
+	}
+	access := entries[1]
+	if access.Message != AccessLogMessage || access.Level != InfoLevel || access.TraceId != "123" {
+		t.Errorf("have:(%q, %v, %q), want:(%q, info, 123)", access.Message, access.Level, access.TraceId, AccessLogMessage)
+	}
+	want := map[string]interface{}{
+		"method":      http.MethodGet,
+		"path":        "/users",
+		"status":      http.StatusOK,
+		"bytes":       int64(5),
+		"remote_addr": req.RemoteAddr,
+	}
+	for k, v := range want {
+		if have := access.Fields[k]; have != v {
+			t.Errorf("%s have:%v, want:%v", k, have, v)
+		}
+	}
+	if _, ok := access.Fields["latency"].(time.Duration); !ok {
+		t.Errorf("have:%v, want time.Duration", access.Fields["latency"])
+	}
+	if lg.(*logger).TraceId() != "" {
+		t.Error("the base logger must not be modified")
+	}
+
+	// generated trace id and the error status
+	entries = nil
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fail", nil))
+	traceId := rec.Header().Get(trace.TraceIdHeaderKey)
+	if traceId == "" {
+		t.Error("want the generated trace id")
+	}
+	if len(entries) != 2 || entries[1].Level != ErrorLevel || entries[1].TraceId != traceId || entries[1].Fields["status"] != http.StatusBadGateway {
+		t.Errorf("have:%v, want the error access log with %q", entries, traceId)
+	}
+
+	// skipped
+	entries = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if len(entries) != 1 || entries[0].Message != "handler" {
+		t.Errorf("have:%v, want only the handler entry", entries)
+	}
+}
+
+func TestMiddleware_SkipFunc(t *testing.T) {
+	var entries []Entry
+	lg := New(WithFormatter(testFuncFormatter(func(entry *Entry) {
+		entries = append(entries, *entry)
+	})))
+	handler := Middleware(lg, WithAccessLevel(DebugLevel), WithSkipFunc(func(req *http.Request) bool {
+		return req.Method == http.MethodOptions
+	}))(http.NotFoundHandler())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodOptions, "/", nil))
+	if len(entries) != 0 {
+		t.Errorf("have:%v, want no entry", entries)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(entries) != 1 || entries[0].Level != DebugLevel || entries[0].Fields["status"] != http.StatusNotFound {
+		t.Errorf("have:%v, want the debug access log with 404", entries)
+	}
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	var entries []Entry
+	lg := New(WithFormatter(testFuncFormatter(func(entry *Entry) {
+		entries = append(entries, *entry)
+	})))
+	handler := Middleware(lg)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("partial"))
+		panic("boom")
+	}))
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("have:%v, want:boom", p)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("have:%d entries, want:1", len(entries))
+		}
+		access := entries[0]
+		if access.Message != AccessLogMessage || access.Level != ErrorLevel {
+			t.Errorf("have:(%q, %v), want:(%q, error)", access.Message, access.Level, AccessLogMessage)
+		}
+		if have := access.Fields["status"]; have != http.StatusInternalServerError {
+			t.Errorf("have:%v, want:%d", have, http.StatusInternalServerError)
+		}
+		if have := access.Fields["panic"]; have != "boom" {
+			t.Errorf("have:%v, want:boom", have)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("want panic")
+}
+
+type testFuncFormatter func(entry *Entry)
+
+func (f testFuncFormatter) Format(entry *Entry) ([]byte, error) {
+	f(entry)
+	return nil, nil
+}
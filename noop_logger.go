@@ -9,6 +9,11 @@ type NoopLogger struct{}
 func (NoopLogger) Fatal(msg string, fields ...interface{}) {
 }
 
+// Panic impl Logger Panic, it panics with msg since the callers do not expect Panic to return.
+func (NoopLogger) Panic(msg string, fields ...interface{}) {
+	panic(msg)
+}
+
 // Error impl Logger Error
 func (NoopLogger) Error(msg string, fields ...interface{}) {
 }
@@ -25,8 +30,15 @@ func (NoopLogger) Info(msg string, fields ...interface{}) {
 func (NoopLogger) Debug(msg string, fields ...interface{}) {
 }
 
-// Output impl Logger Output
+// Trace impl Logger Trace
+func (NoopLogger) Trace(msg string, fields ...interface{}) {
+}
+
+// Output impl Logger Output, it panics with msg for PanicLevel, see Panic.
 func (NoopLogger) Output(calldepth int, level Level, msg string, fields ...interface{}) {
+	if level == PanicLevel {
+		panic(msg)
+	}
 }
 
 // WithField impl Logger WithField
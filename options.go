@@ -1,7 +1,10 @@
 package log
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"runtime"
 	"sync/atomic"
 	"unsafe"
 )
@@ -62,11 +65,45 @@ func WithLevelString(str string) Option {
 	}
 }
 
+// WithStacktrace makes the logger capture the stack of the entries with level or higher level (see Entry.Stack),
+// for example WithStacktrace(ErrorLevel) captures the stack of the FatalLevel and ErrorLevel entries.
+func WithStacktrace(level Level) Option {
+	return func(o *options) {
+		if !isValidLevel(level) {
+			return
+		}
+		o.stackLevel = level
+	}
+}
+
+// WithExitOnFatal makes the logger call exitFunc(1) after a FatalLevel entry is logged and the logger is flushed
+// (see Flush), os.Exit is used if exitFunc is nil.
+func WithExitOnFatal(exitFunc func(code int)) Option {
+	return func(o *options) {
+		if exitFunc == nil {
+			exitFunc = os.Exit
+		}
+		o.exitFunc = &exitFunc
+	}
+}
+
 type options struct {
 	traceId   string
 	formatter Formatter
 	output    io.Writer
 	level     Level
+	async     *asyncWriter
+
+	levelRoutes *levelRoutes
+	sinks       *sinks
+	sampler     *sampler
+	hooks       *hooks
+	redactor    *redactor
+	stackLevel  Level           // invalidLevel means no stack is captured
+	exitFunc    *func(code int) // pointer to keep options comparable
+
+	levelOverrides *levelOverrides
+	atomicLevel    *AtomicLevel // takes precedence over level if it is not nil
 }
 
 func (opts *options) SetFormatter(formatter Formatter) {
@@ -88,6 +125,108 @@ func (opts *options) SetLevel(level Level) {
 	opts.level = level
 }
 
+func (opts *options) AddHooks(hooks []Hook) {
+	opts.hooks = opts.hooks.with(hooks)
+}
+
+// getLevel returns the current level of the logger.
+func (opts *options) getLevel() Level {
+	if opts.atomicLevel != nil {
+		return opts.atomicLevel.Level()
+	}
+	return opts.level
+}
+
+func (opts *options) isEnabled(level Level) bool {
+	if !isLevelEnabled(level, opts.getLevel()) {
+		return false
+	}
+	if opts.sinks != nil && !isLevelEnabled(level, opts.sinks.level) {
+		return false
+	}
+	return true
+}
+
+// checkCaller reports whether the entry with level logged by the caller is enabled,
+// the location of the caller is returned if it is computed for the level overrides, otherwise it is empty.
+func (opts *options) checkCaller(calldepth int, level Level) (location string, ok bool) {
+	t := opts.levelOverrides.load()
+	if t == nil {
+		return "", opts.isEnabled(level)
+	}
+	pc, file, line, ok := runtime.Caller(calldepth + 1)
+	if !ok {
+		return "", opts.isEnabled(level)
+	}
+	if !t.enabled(opts, pc, level) {
+		return "", false
+	}
+	return formatLocation(pc, file, line, ok), true
+}
+
+// checkPC is the same as checkCaller but the caller is specified by pc, 0 means unknown.
+func (opts *options) checkPC(pc uintptr, level Level) bool {
+	if t := opts.levelOverrides.load(); t != nil && pc != 0 {
+		return t.enabled(opts, pc, level)
+	}
+	return opts.isEnabled(level)
+}
+
+func (opts *options) isStackEnabled(level Level) bool {
+	return opts.stackLevel != invalidLevel && isLevelEnabled(level, opts.stackLevel)
+}
+
+// write writes the formatted entry to output, data can be reused after write returns.
+func (opts *options) write(output io.Writer, level Level, data []byte) error {
+	if opts.async != nil {
+		return opts.async.write(output, level, data)
+	}
+	_, err := writeLevel(output, level, data)
+	return err
+}
+
+// flush flushes the async writer and then the outputs that implement Flusher.
+func (opts *options) flush() error {
+	var err error
+	if opts.async != nil {
+		err = opts.async.Flush()
+	}
+	flush := func(w io.Writer) {
+		if f, ok := w.(Flusher); ok && w != nil {
+			if err2 := f.Flush(); err2 != nil && err == nil {
+				err = err2
+			}
+		}
+	}
+	if opts.sinks != nil {
+		for _, sink := range opts.sinks.list {
+			flush(sink.Output)
+		}
+		return err
+	}
+	flush(opts.output)
+	if opts.levelRoutes != nil {
+		for _, route := range opts.levelRoutes.routes {
+			flush(route.output)
+		}
+	}
+	return err
+}
+
+// terminate flushes the logger and then exits for FatalLevel if WithExitOnFatal is used, or panics for PanicLevel.
+func (opts *options) terminate(calldepth int, level Level, msg string) {
+	if level == FatalLevel && opts.exitFunc == nil {
+		return
+	}
+	if err := opts.flush(); err != nil {
+		fmt.Fprintf(ConcurrentStderr, "log: failed to flush log, error=%v, location=%s\n", err, callerLocation(calldepth+1))
+	}
+	if level == PanicLevel {
+		panic(msg)
+	}
+	(*opts.exitFunc)(1)
+}
+
 func newOptions(opts []Option) *options {
 	var o options
 	for _, opt := range getDefaultOptions() {
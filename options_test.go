@@ -265,9 +265,9 @@ func TestWithLevel(t *testing.T) {
 			return
 		}
 	}
-	// debug+1
+	// trace+1
 	{
-		opt := WithLevel(DebugLevel + 1)
+		opt := WithLevel(TraceLevel + 1)
 
 		var o = options{
 			level: FatalLevel,
@@ -284,9 +284,9 @@ func TestWithLevel(t *testing.T) {
 }
 
 func TestWithLevelString(t *testing.T) {
-	// panic
+	// unknown
 	{
-		opt := WithLevelString("panic")
+		opt := WithLevelString("unknown")
 
 		var o = options{
 			level: DebugLevel,
@@ -382,7 +382,7 @@ func TestWithLevelString(t *testing.T) {
 	}
 	// trace
 	{
-		opt := WithLevelString("trace")
+		opt := WithLevelString("unknown")
 
 		var o = options{
 			level: FatalLevel,
@@ -557,12 +557,12 @@ func TestOptions_SetLevel(t *testing.T) {
 			return
 		}
 	}
-	// debug+1
+	// trace+1
 	{
 		var o = options{
 			level: FatalLevel,
 		}
-		o.SetLevel(DebugLevel + 1)
+		o.SetLevel(TraceLevel + 1)
 
 		want := options{
 			level: FatalLevel,
@@ -0,0 +1,425 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultRedactMask is the default mask of the redacted values.
+const DefaultRedactMask = "******"
+
+// RedactOption configures the redaction of WithRedaction.
+type RedactOption func(*redactor)
+
+// WithRedaction makes the logger redact the sensitive data of the entries before the hooks are fired
+// and the entries are formatted:
+//  1. the value of a field whose key matches a key pattern (see WithRedactKeys) is replaced entirely,
+//     whatever its type is.
+//  2. the parts of the message and the string values of the fields (including the error and fmt.Stringer values)
+//     that match a value pattern (see WithRedactValues) are replaced.
+//
+// The values are replaced by the mask (see WithRedactMask) or the hash (see WithRedactHash).
+// WithRedaction can be used multiple times, the rules are accumulated.
+//
+// The values rendered by the JSON and XML helpers, the Object fields and the struct values encoded by
+// JsonFormatter are redacted by the struct tags, see JSON.
+func WithRedaction(opts ...RedactOption) Option {
+	return func(o *options) {
+		r := o.redactor.clone()
+		for _, opt := range opts {
+			if opt == nil {
+				continue
+			}
+			opt(r)
+		}
+		if len(r.keys) == 0 && len(r.keyGlobs) == 0 && len(r.values) == 0 {
+			return
+		}
+		o.redactor = r
+	}
+}
+
+// WithRedactKeys adds the key patterns, the keys are matched case-insensitively,
+// the syntax of the patterns is the same as path.Match, for example "password" and "*_token".
+func WithRedactKeys(patterns ...string) RedactOption {
+	return func(r *redactor) {
+		for _, pattern := range patterns {
+			pattern = strings.ToLower(pattern)
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				continue
+			}
+			if strings.ContainsAny(pattern, `*?[\`) {
+				r.keyGlobs = append(r.keyGlobs, pattern)
+				continue
+			}
+			r.keys[pattern] = struct{}{}
+		}
+	}
+}
+
+// WithRedactValues adds the value patterns.
+func WithRedactValues(patterns ...*regexp.Regexp) RedactOption {
+	return func(r *redactor) {
+		for _, re := range patterns {
+			if re == nil {
+				continue
+			}
+			r.values = append(r.values, re)
+		}
+	}
+}
+
+// WithRedactMask sets the mask of the redacted values, the default is DefaultRedactMask.
+func WithRedactMask(mask string) RedactOption {
+	return func(r *redactor) {
+		r.mask = mask
+		r.hash = false
+	}
+}
+
+// WithRedactHash makes the redacted values replaced by "sha256:" and the first 16 hex digits
+// of their SHA-256 hash, so the same values can still be correlated.
+func WithRedactHash() RedactOption {
+	return func(r *redactor) {
+		r.hash = true
+	}
+}
+
+var (
+	_defaultRedactKeys = []string{
+		"password", "passwd", "pwd", "*_password",
+		"secret", "*_secret",
+		"token", "*_token", "*-token",
+		"authorization", "cookie", "set-cookie",
+		"api_key", "apikey", "private_key",
+		"id_card", "idcard", "id_number",
+		"credit_card", "card_number", "cvv",
+	}
+	_defaultRedactValues = []*regexp.Regexp{
+		// mobile phone number of China
+		regexp.MustCompile(`\b1[3-9]\d{9}\b`),
+		// resident identity card number of China
+		regexp.MustCompile(`\b[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+		// email address
+		regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+		// bearer token of the Authorization header
+		regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	}
+)
+
+// WithDefaultRedactRules adds the built-in rules for the common credentials and PII:
+// the keys such as password, token, secret, authorization, cookie, id_card and credit_card,
+// and the values such as phone numbers, identity card numbers, email addresses and bearer tokens.
+func WithDefaultRedactRules() RedactOption {
+	return func(r *redactor) {
+		WithRedactKeys(_defaultRedactKeys...)(r)
+		WithRedactValues(_defaultRedactValues...)(r)
+	}
+}
+
+// redactor is immutable after it is built by WithRedaction.
+type redactor struct {
+	keys     map[string]struct{}
+	keyGlobs []string
+	values   []*regexp.Regexp
+	mask     string
+	hash     bool
+}
+
+func (r *redactor) clone() *redactor {
+	r2 := &redactor{
+		keys: make(map[string]struct{}),
+		mask: DefaultRedactMask,
+	}
+	if r == nil {
+		return r2
+	}
+	for k := range r.keys {
+		r2.keys[k] = struct{}{}
+	}
+	r2.keyGlobs = append(r2.keyGlobs, r.keyGlobs...)
+	r2.values = append(r2.values, r.values...)
+	r2.mask = r.mask
+	r2.hash = r.hash
+	return r2
+}
+
+// redact redacts the message and FieldList of the entry, FieldList is copied before it is modified.
+func (r *redactor) redact(entry *Entry) {
+	if r == nil {
+		return
+	}
+	if msg, ok := r.redactString(entry.Message); ok {
+		entry.Message = msg
+	}
+	var list []Field
+	for i := range entry.FieldList {
+		f, ok := r.redactField(&entry.FieldList[i])
+		if !ok {
+			continue
+		}
+		if list == nil {
+			list = make([]Field, len(entry.FieldList))
+			copy(list, entry.FieldList)
+		}
+		list[i] = f
+	}
+	if list != nil {
+		entry.FieldList = list
+	}
+}
+
+func (r *redactor) redactField(f *Field) (Field, bool) {
+	if r.matchKey(f.Key) {
+		if !r.hash {
+			return String(f.Key, r.mask), true
+		}
+		if f.Type == StringType {
+			return String(f.Key, redactHash(f.String)), true
+		}
+		return String(f.Key, redactHash(fmt.Sprint(f.Interface()))), true
+	}
+	if len(r.values) == 0 {
+		return Field{}, false
+	}
+	var s string
+	switch f.Type {
+	case StringType:
+		s = f.String
+	case UnknownType:
+		switch v := f.Value.(type) {
+		case string:
+			s = v
+		case error:
+			if isNilPointer(v) {
+				return Field{}, false
+			}
+			s = v.Error()
+		case fmt.Stringer:
+			if isNilPointer(v) {
+				return Field{}, false
+			}
+			s = v.String()
+		default:
+			return Field{}, false
+		}
+	case ErrorType:
+		if f.Value == nil || isNilPointer(f.Value) {
+			return Field{}, false
+		}
+		s = f.Value.(error).Error()
+	case StringerType:
+		if f.Value == nil || isNilPointer(f.Value) {
+			return Field{}, false
+		}
+		s = f.Value.(fmt.Stringer).String()
+	default:
+		return Field{}, false
+	}
+	if s, ok := r.redactString(s); ok {
+		return String(f.Key, s), true
+	}
+	return Field{}, false
+}
+
+func (r *redactor) matchKey(key string) bool {
+	if len(r.keys) == 0 && len(r.keyGlobs) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	if _, ok := r.keys[key]; ok {
+		return true
+	}
+	for _, pattern := range r.keyGlobs {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces the parts of s that match the value patterns, ok reports whether s is changed.
+func (r *redactor) redactString(s string) (string, bool) {
+	var changed bool
+	for _, re := range r.values {
+		if !re.MatchString(s) {
+			continue
+		}
+		s = re.ReplaceAllStringFunc(s, r.conceal)
+		changed = true
+	}
+	return s, changed
+}
+
+func (r *redactor) conceal(s string) string {
+	if r.hash {
+		return redactHash(s)
+	}
+	return r.mask
+}
+
+func redactHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// The values of the struct fields with the following tags are redacted by the JSON and XML helpers.
+const (
+	_redactTagKey  = "log"
+	_redactTagMask = "redact" // replaced by DefaultRedactMask
+	_redactTagHash = "hash"   // replaced by the hash, see WithRedactHash
+)
+
+var _redactTypeCache sync.Map // map[reflect.Type]bool
+
+// redactTagged returns a copy of v with the values of the tagged struct fields redacted,
+// v is returned as is if its type has no tagged struct field.
+//
+// The tagged string values are replaced by the mask or hash, the other tagged values are replaced by the zero values.
+// The values of the interface types and the fields of the unexported embedded structs are not inspected.
+func redactTagged(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !hasRedactTags(rv.Type()) {
+		return v
+	}
+	return redactTaggedValue(rv, 0).Interface()
+}
+
+func hasRedactTags(t reflect.Type) bool {
+	if v, ok := _redactTypeCache.Load(t); ok {
+		return v.(bool)
+	}
+	has := hasRedactTagsVisit(t, make(map[reflect.Type]bool))
+	_redactTypeCache.Store(t, has)
+	return has
+}
+
+func hasRedactTagsVisit(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasRedactTagsVisit(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" { // unexported
+				continue
+			}
+			if tag := sf.Tag.Get(_redactTagKey); tag == _redactTagMask || tag == _redactTagHash {
+				return true
+			}
+			if hasRedactTagsVisit(sf.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// _redactMaxDepth limits the depth of redactTaggedValue, the values with cyclic pointers are not redacted beyond it.
+const _redactMaxDepth = 64
+
+func redactTaggedValue(v reflect.Value, depth int) reflect.Value {
+	t := v.Type()
+	if depth > _redactMaxDepth || !hasRedactTags(t) {
+		return v
+	}
+	depth++
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		nv := reflect.New(t.Elem())
+		nv.Elem().Set(redactTaggedValue(v.Elem(), depth))
+		return nv
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			nv.Index(i).Set(redactTaggedValue(v.Index(i), depth))
+		}
+		return nv
+	case reflect.Array:
+		nv := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			nv.Index(i).Set(redactTaggedValue(v.Index(i), depth))
+		}
+		return nv
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		nv := reflect.MakeMapWithSize(t, v.Len())
+		for _, k := range v.MapKeys() {
+			nv.SetMapIndex(k, redactTaggedValue(v.MapIndex(k), depth))
+		}
+		return nv
+	case reflect.Struct:
+		nv := reflect.New(t).Elem()
+		nv.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			switch sf.Tag.Get(_redactTagKey) {
+			case _redactTagMask:
+				nv.Field(i).Set(concealValue(v.Field(i), false))
+			case _redactTagHash:
+				nv.Field(i).Set(concealValue(v.Field(i), true))
+			default:
+				nv.Field(i).Set(redactTaggedValue(v.Field(i), depth))
+			}
+		}
+		return nv
+	default:
+		return v
+	}
+}
+
+// concealValue returns the redacted copy of the tagged value v.
+func concealValue(v reflect.Value, hash bool) reflect.Value {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.String:
+		nv := reflect.New(t).Elem()
+		if hash {
+			nv.SetString(redactHash(v.String()))
+		} else {
+			nv.SetString(DefaultRedactMask)
+		}
+		return nv
+	case reflect.Ptr:
+		if v.IsNil() || t.Elem().Kind() != reflect.String {
+			return reflect.Zero(t)
+		}
+		nv := reflect.New(t.Elem())
+		nv.Elem().Set(concealValue(v.Elem(), hash))
+		return nv
+	case reflect.Slice:
+		if v.IsNil() || t.Elem().Kind() != reflect.String {
+			return reflect.Zero(t)
+		}
+		nv := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			nv.Index(i).Set(concealValue(v.Index(i), hash))
+		}
+		return nv
+	default:
+		return reflect.Zero(t)
+	}
+}
@@ -0,0 +1,155 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestLogger_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	lg := New(
+		WithOutput(&buf),
+		WithFormatter(NewTextFormatter(WithoutFields(TimeField|LocationField|TraceIdField), WithFieldOrder(InsertionFieldOrder))),
+		WithRedaction(WithDefaultRedactRules()),
+		WithRedaction(WithRedactKeys("X-*"), WithRedactValues(regexp.MustCompile(`order-\d+`))),
+	).WithField("Password", "secret")
+
+	lg.Info("call 13812345678 for order-42",
+		"access_token", 12345,
+		"x-request", "abc",
+		"email", "name: keke@example.com",
+		"error", errors.New("invalid id 110101199003071234"),
+		"count", 1,
+		"user", "keke",
+	)
+	want := "level=info, msg=call ****** for ******, Password=******, access_token=******, x-request=******, " +
+		"email=name: ******, error=invalid id ******, count=1, user=keke\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	// the fields of the logger are not modified
+	if have := lg.(*logger).fields[0].Value; have != "secret" {
+		t.Errorf("have:%v, want:secret", have)
+	}
+}
+
+func TestLogger_RedactionHash(t *testing.T) {
+	var buf bytes.Buffer
+	lg := NewFieldLogger(
+		WithOutput(&buf),
+		WithFormatter(NewJsonFormatter(WithoutFields(TimeField|LocationField|TraceIdField|LevelField))),
+		WithRedaction(WithRedactKeys("token"), WithRedactHash()),
+	)
+	lg.InfoF("msg", String("token", "abc"), Int("n", 1))
+	want := `{"msg":"msg","n":1,"token":"` + redactHash("abc") + `"}` + "\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	if have := redactHash("abc"); len(have) != len("sha256:")+16 {
+		t.Errorf("not expected hash: %s", have)
+	}
+}
+
+func TestLogger_RedactionNilPointer(t *testing.T) {
+	var buf bytes.Buffer
+	lg := NewFieldLogger(
+		WithOutput(&buf),
+		WithFormatter(NewJsonFormatter(WithoutFields(TimeField|LocationField|TraceIdField|LevelField))),
+		WithRedaction(WithDefaultRedactRules()),
+	)
+	lg.InfoF("msg", Any("a", (*testPtrStringer)(nil)), Stringer("b", (*testPtrStringer)(nil)), NamedErr("c", (*testError)(nil)))
+	want := `{"msg":"msg","a":null,"b":null,"c":null}` + "\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
+
+func TestJsonFormatter_RedactTagged(t *testing.T) {
+	var jsonBuf, textBuf bytes.Buffer
+	u := testRedactUser{Name: "keke", Password: "secret"}
+	jsonLogger := NewFieldLogger(
+		WithOutput(&jsonBuf),
+		WithFormatter(NewJsonFormatter(WithoutFields(TimeField|LocationField|TraceIdField|LevelField))),
+	)
+	textLogger := NewFieldLogger(
+		WithOutput(&textBuf),
+		WithFormatter(NewTextFormatter(WithoutFields(TimeField|LocationField|TraceIdField|LevelField))),
+	)
+	jsonLogger.InfoF("msg", Object("user", u))
+	textLogger.InfoF("msg", Object("user", u))
+	want := `{"name":"keke","password":"******","phone":null,"age":0,"tags":null}`
+	if have := jsonBuf.String(); have != `{"msg":"msg","user":`+want+"}\n" {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	if have := textBuf.String(); have != "msg=msg, user="+want+"\n" {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	// the values encoded by encoding/json
+	jsonBuf.Reset()
+	jsonLogger.InfoF("msg", Any("user", &u))
+	if have := jsonBuf.String(); have != `{"msg":"msg","user":`+want+"}\n" {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	if u.Password != "secret" {
+		t.Errorf("v is modified: %+v", u)
+	}
+}
+
+func TestWithRedaction_NoRules(t *testing.T) {
+	opts := newOptions([]Option{WithRedaction(WithRedactKeys("", "[")), WithRedaction(WithRedactMask("x"))})
+	if opts.redactor != nil {
+		t.Error("want nil")
+	}
+}
+
+type testRedactUser struct {
+	Name     string            `json:"name" xml:"name"`
+	Password string            `json:"password" xml:"password" log:"redact"`
+	Phone    *string           `json:"phone" xml:"phone" log:"hash"`
+	Age      int               `json:"age" xml:"age" log:"redact"`
+	Tags     []string          `json:"tags" xml:"tags" log:"redact"`
+	Friends  []*testRedactUser `json:"friends,omitempty" xml:"friends,omitempty"`
+}
+
+func TestJSON_Redact(t *testing.T) {
+	phone := "13812345678"
+	u := &testRedactUser{
+		Name:     "keke",
+		Password: "secret",
+		Phone:    &phone,
+		Age:      18,
+		Tags:     []string{"a", "b"},
+		Friends:  []*testRedactUser{{Name: "li", Password: "secret2"}},
+	}
+	have := JSON(u)
+	want := `{"name":"keke","password":"******","phone":"` + redactHash(phone) + `","age":0,"tags":["******","******"],` +
+		`"friends":[{"name":"li","password":"******","phone":null,"age":0,"tags":null}]}`
+	if have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	// v is not modified
+	if u.Password != "secret" || *u.Phone != phone || u.Age != 18 || u.Tags[0] != "a" || u.Friends[0].Password != "secret2" {
+		t.Errorf("v is modified: %+v", u)
+	}
+
+	have = XML(struct {
+		XMLName struct{}       `xml:"msg"`
+		User    testRedactUser `xml:"user"`
+	}{User: testRedactUser{Name: "keke", Password: "secret"}})
+	want = `<msg><user><name>keke</name><password>******</password><age>0</age></user></msg>`
+	if have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	// map and types without tags
+	if have, want := JSON(map[string]testRedactUser{"u": {Password: "p"}}), `{"u":{"name":"","password":"******","phone":null,"age":0,"tags":null}}`; have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	if have, want := JSON(map[string]string{"password": "p"}), `{"password":"p"}`; have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
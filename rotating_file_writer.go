@@ -0,0 +1,393 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateInterval is the wall-clock interval of RotatingFileWriter.
+type RotateInterval int
+
+const (
+	RotateNever RotateInterval = iota
+	RotateHourly
+	RotateDaily
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+type RotatingFileOption func(*rotatingFileOptions)
+
+// WithMaxSize sets the maximum size in bytes of the log file before it gets rotated, 0 means no limit.
+func WithMaxSize(size int64) RotatingFileOption {
+	return func(o *rotatingFileOptions) {
+		if size < 0 {
+			return
+		}
+		o.maxSize = size
+	}
+}
+
+// WithRotateInterval sets the wall-clock interval of rotation.
+func WithRotateInterval(interval RotateInterval) RotatingFileOption {
+	return func(o *rotatingFileOptions) {
+		o.interval = interval
+	}
+}
+
+// WithRotateLocation sets the time zone used to decide the hour or day boundary and to name the backups,
+// the default is Asia/Shanghai.
+func WithRotateLocation(loc *time.Location) RotatingFileOption {
+	return func(o *rotatingFileOptions) {
+		if loc == nil {
+			return
+		}
+		o.location = loc
+	}
+}
+
+// WithMaxBackups sets the maximum number of backups to keep, 0 means keeping all of them.
+func WithMaxBackups(n int) RotatingFileOption {
+	return func(o *rotatingFileOptions) {
+		if n < 0 {
+			return
+		}
+		o.maxBackups = n
+	}
+}
+
+// WithCompress makes the rotated files gzipped in the background.
+func WithCompress(compress bool) RotatingFileOption {
+	return func(o *rotatingFileOptions) {
+		o.compress = compress
+	}
+}
+
+type rotatingFileOptions struct {
+	maxSize    int64
+	interval   RotateInterval
+	location   *time.Location
+	maxBackups int
+	compress   bool
+}
+
+var _ io.WriteCloser = (*RotatingFileWriter)(nil)
+
+// RotatingFileWriter is an io.Writer that writes to a file and rotates it by size and/or by wall-clock interval.
+// The rotated file is renamed to name-2006-01-02T15-04-05.000.ext, where name.ext is the original file name.
+//
+// RotatingFileWriter is safe for concurrent use, it is not necessary to wrap it with ConcurrentWriter.
+type RotatingFileWriter struct {
+	filename string
+	opts     rotatingFileOptions
+	now      func() time.Time
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time // zero if RotateNever
+
+	millWg sync.WaitGroup
+	millMu sync.Mutex // serializes compressing and removing backups
+}
+
+// NewRotatingFileWriter opens (or creates) filename for appending and returns a RotatingFileWriter.
+func NewRotatingFileWriter(filename string, opts ...RotatingFileOption) (*RotatingFileWriter, error) {
+	if filename == "" {
+		return nil, errors.New("log: empty file name")
+	}
+	w := &RotatingFileWriter{
+		filename: filename,
+		opts: rotatingFileOptions{
+			location: _beijingLocation,
+		},
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&w.opts)
+	}
+	if err := w.openExisting(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingFileWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err = w.rotate(); err != nil {
+			if w.file == nil {
+				return 0, err
+			}
+			fmt.Fprintf(ConcurrentStderr, "log: failed to rotate file, error=%v, file=%s\n", err, w.filename)
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it to a backup and opens a new one,
+// the current file is reopened for appending if the rotation fails.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the current file and waits for the background compression to finish.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+
+	w.millWg.Wait()
+	return err
+}
+
+func (w *RotatingFileWriter) shouldRotate(n int64) bool {
+	if w.opts.maxSize > 0 && w.size > 0 && w.size+n > w.opts.maxSize {
+		return true
+	}
+	if !w.nextRotation.IsZero() && !w.now().Before(w.nextRotation) {
+		return true
+	}
+	return false
+}
+
+func (w *RotatingFileWriter) openExisting() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	if w.size > 0 {
+		// the existing file may have been written in an earlier period
+		w.nextRotation = w.periodEnd(info.ModTime())
+	} else {
+		w.nextRotation = w.periodEnd(w.now())
+	}
+	return nil
+}
+
+// _rename renames the rotated files, it is replaced in the tests.
+var _rename = os.Rename
+
+// rotate requires w.mu to be held.
+// If the rotation fails, the current file is reopened for appending, so the later writes are not lost,
+// and the next rotation by interval is postponed to the next period.
+func (w *RotatingFileWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+
+	now := w.now()
+	backup := w.backupName(now)
+	renamed := false
+	if err == nil {
+		if err = _rename(w.filename, backup); err == nil {
+			renamed = true
+		} else if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err == nil {
+		var file *os.File
+		if file, err = os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644); err == nil {
+			w.file = file
+			w.size = 0
+		}
+	}
+	if w.file == nil {
+		if err2 := w.openExisting(); err2 != nil {
+			err = fmt.Errorf("%v, failed to reopen %s: %v", err, w.filename, err2)
+		}
+	}
+	w.nextRotation = w.periodEnd(now)
+
+	if renamed {
+		w.millWg.Add(1)
+		go w.mill(backup)
+	}
+	return err
+}
+
+// periodEnd returns the start of the period next to the one containing t.
+func (w *RotatingFileWriter) periodEnd(t time.Time) time.Time {
+	t = t.In(w.opts.location)
+	switch w.opts.interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(time.Hour)
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+func (w *RotatingFileWriter) backupName(t time.Time) string {
+	dir, name := filepath.Split(w.filename)
+	ext := filepath.Ext(name)
+	prefix := name[:len(name)-len(ext)]
+	timestamp := t.In(w.opts.location).Format(backupTimeFormat)
+
+	backup := filepath.Join(dir, prefix+"-"+timestamp+ext)
+	for i := 2; ; i++ {
+		_, err := os.Stat(backup)
+		if os.IsNotExist(err) {
+			_, err = os.Stat(backup + compressSuffix)
+		}
+		if os.IsNotExist(err) {
+			return backup
+		}
+		backup = filepath.Join(dir, fmt.Sprintf("%s-%s.%d%s", prefix, timestamp, i, ext))
+	}
+}
+
+// mill compresses the backup if necessary and removes the old backups.
+func (w *RotatingFileWriter) mill(backup string) {
+	defer w.millWg.Done()
+
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	if w.opts.compress {
+		if err := compressFile(backup); err != nil {
+			fmt.Fprintf(ConcurrentStderr, "log: failed to compress log file, error=%v, file=%s\n", err, backup)
+		}
+	}
+	if w.opts.maxBackups > 0 {
+		backups, err := w.backups()
+		if err != nil {
+			fmt.Fprintf(ConcurrentStderr, "log: failed to list log backups, error=%v, file=%s\n", err, w.filename)
+			return
+		}
+		for len(backups) > w.opts.maxBackups {
+			if err = os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(ConcurrentStderr, "log: failed to remove log backup, error=%v, file=%s\n", err, backups[0])
+			}
+			backups = backups[1:]
+		}
+	}
+}
+
+// backups returns the backups of the file, the oldest first.
+func (w *RotatingFileWriter) backups() ([]string, error) {
+	dir, name := filepath.Split(w.filename)
+	ext := filepath.Ext(name)
+	prefix := name[:len(name)-len(ext)] + "-"
+
+	if dir == "" {
+		dir = "."
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		name string
+		time time.Time
+	}
+	var list []backup
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		str := info.Name()
+		if !strings.HasPrefix(str, prefix) {
+			continue
+		}
+		str = strings.TrimSuffix(str[len(prefix):], compressSuffix)
+		if !strings.HasSuffix(str, ext) {
+			continue
+		}
+		str = str[:len(str)-len(ext)]
+		if i := strings.LastIndexByte(str, '.'); i > len(backupTimeFormat)-len(".000") {
+			str = str[:i] // the .2, .3 ... suffix of backupName
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, str, w.opts.location)
+		if err != nil {
+			continue
+		}
+		list = append(list, backup{name: filepath.Join(dir, info.Name()), time: t})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].time.Equal(list[j].time) {
+			return list[i].name < list[j].name
+		}
+		return list[i].time.Before(list[j].time)
+	})
+	names := make([]string, len(list))
+	for i := range list {
+		names[i] = list[i].name
+	}
+	return names, nil
+}
+
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(name + compressSuffix)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,182 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func testReadDir(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingFileWriter_MaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), WithMaxSize(10), WithMaxBackups(2))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	now := time.Date(2018, time.May, 20, 8, 20, 30, 0, time.UTC)
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	for _, s := range []string{"123456\n", "abcdef\n", "ABCDEF\n", "654321\n"} {
+		if _, err = w.Write([]byte(s)); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	have := testReadDir(t, dir)
+	want := []string{
+		"app-2018-05-20T16-20-32.000.log", // abcdef
+		"app-2018-05-20T16-20-33.000.log", // ABCDEF
+		"app.log",
+	}
+	if len(have) != len(want) {
+		t.Fatalf("have:%v, want:%v", have, want)
+	}
+	for i := range have {
+		if have[i] != want[i] {
+			t.Fatalf("have:%v, want:%v", have, want)
+		}
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "app-2018-05-20T16-20-33.000.log"))
+	if string(data) != "ABCDEF\n" {
+		t.Errorf("have:%q, want:%q", data, "ABCDEF\n")
+	}
+	data, _ = ioutil.ReadFile(filepath.Join(dir, "app.log"))
+	if string(data) != "654321\n" {
+		t.Errorf("have:%q, want:%q", data, "654321\n")
+	}
+}
+
+func TestRotatingFileWriter_Interval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, time.May, 20, 8, 20, 30, 0, time.UTC)
+	w := &RotatingFileWriter{
+		filename: filepath.Join(dir, "app.log"),
+		opts: rotatingFileOptions{
+			interval: RotateHourly,
+			location: time.UTC,
+			compress: true,
+		},
+		now: func() time.Time { return now },
+	}
+	if err = w.openExisting(); err != nil {
+		t.Fatal(err.Error())
+	}
+	w.Write([]byte("08\n"))
+	now = now.Add(30 * time.Minute)
+	w.Write([]byte("08\n"))
+	now = now.Add(10 * time.Minute)
+	w.Write([]byte("09\n"))
+	w.Close()
+
+	have := testReadDir(t, dir)
+	if len(have) != 2 || have[0] != "app-2018-05-20T09-00-30.000.log.gz" || have[1] != "app.log" {
+		t.Fatalf("not expected files: %v", have)
+	}
+	file, err := os.Open(filepath.Join(dir, have[0]))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	data, _ := ioutil.ReadAll(zr)
+	if string(data) != "08\n08\n" {
+		t.Errorf("have:%q, want:%q", data, "08\n08\n")
+	}
+}
+
+func TestRotatingFileWriter_RotateFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), WithMaxSize(10))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer w.Close()
+
+	renameErr := errors.New("rename failed")
+	_rename = func(string, string) error { return renameErr }
+	defer func() { _rename = os.Rename }()
+
+	if err = w.Rotate(); err != renameErr {
+		t.Errorf("have:%v, want:%v", err, renameErr)
+	}
+	// the writes continue on the current file
+	for _, s := range []string{"123456\n", "abcdef\n"} {
+		if _, err = w.Write([]byte(s)); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "app.log"))
+	if string(data) != "123456\nabcdef\n" {
+		t.Errorf("have:%q, want:%q", data, "123456\nabcdef\n")
+	}
+
+	// rotated after the rename succeeds
+	_rename = os.Rename
+	if _, err = w.Write([]byte("ABCDEF\n")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if have := testReadDir(t, dir); len(have) != 2 {
+		t.Errorf("have:%v, want the backup and app.log", have)
+	}
+	data, _ = ioutil.ReadFile(filepath.Join(dir, "app.log"))
+	if string(data) != "ABCDEF\n" {
+		t.Errorf("have:%q, want:%q", data, "ABCDEF\n")
+	}
+}
+
+func TestRotatingFileWriter_Closed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "sub", "app.log"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	w.Close()
+	if _, err = w.Write([]byte("123")); err != os.ErrClosed {
+		t.Errorf("have:%v, want:%v", err, os.ErrClosed)
+	}
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SampledField is the key of the field that holds the number of the entries suppressed by WithSampling.
+const SampledField = "suppressed"
+
+// WithSampling limits the entries with the same level and message, in every tick interval
+// the first first entries are logged, after that only every thereafter-th entry is logged,
+// if thereafter is 0, all the entries after the first first entries are dropped.
+//
+// When the next interval of a level and message starts, an entry with the same level and message
+// and the field SampledField is logged first if any entry has been suppressed in the previous interval.
+// If the level and message do not recur, the suppressed entries are reported by the next entry
+// logged after the interval ends, or by Flush and Close; the location of such an entry is unknown
+// and it has no fields other than SampledField.
+//
+// The entries are sampled before they are formatted, so the suppressed entries cost very little.
+// The counters are kept in a fixed size table indexed by the hash of level and message,
+// different messages may share a counter in rare cases.
+func WithSampling(tick time.Duration, first, thereafter int) Option {
+	return func(o *options) {
+		if tick <= 0 || first < 0 || thereafter < 0 {
+			return
+		}
+		o.sampler = &sampler{
+			tick:       int64(tick),
+			first:      uint64(first),
+			thereafter: uint64(thereafter),
+		}
+	}
+}
+
+const _samplerCounters = 4096
+
+type sampleCounter struct {
+	resetAt    int64  // atomic, UnixNano
+	count      uint64 // atomic
+	suppressed uint64 // atomic
+}
+
+type sampler struct {
+	tick       int64
+	first      uint64
+	thereafter uint64
+	counters   [_samplerCounters]sampleCounter
+	flushAt    int64 // atomic, UnixNano, the next time to check the unreported counters
+
+	mu         sync.Mutex
+	unreported map[uint32]sampleSummary // the counters with the suppressed entries, indexed by the counter index
+}
+
+// sampleSummary is the number of the entries with level and msg suppressed by sampler.
+type sampleSummary struct {
+	level      Level
+	msg        string
+	suppressed uint64
+}
+
+// sample reports whether the entry should be logged,
+// suppressed is the number of the entries suppressed in the previous interval of the counter.
+// sample of the nil sampler always returns true.
+func (s *sampler) sample(level Level, msg string, now time.Time) (suppressed uint64, ok bool) {
+	if s == nil {
+		return 0, true
+	}
+	i := sampleHash(level, msg) % _samplerCounters
+	c := &s.counters[i]
+
+	var n uint64
+	tn := now.UnixNano()
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > tn || !atomic.CompareAndSwapInt64(&c.resetAt, resetAt, tn+s.tick) {
+		n = atomic.AddUint64(&c.count, 1)
+	} else {
+		atomic.StoreUint64(&c.count, 1)
+		suppressed = atomic.SwapUint64(&c.suppressed, 0)
+		n = 1
+	}
+	if n <= s.first || s.thereafter > 0 && (n-s.first)%s.thereafter == 0 {
+		return suppressed, true
+	}
+	// carry the previous interval over if this entry is dropped
+	if atomic.AddUint64(&c.suppressed, suppressed+1) == suppressed+1 {
+		s.mu.Lock()
+		if s.unreported == nil {
+			s.unreported = make(map[uint32]sampleSummary)
+		}
+		s.unreported[i] = sampleSummary{level: level, msg: msg}
+		s.mu.Unlock()
+	}
+	return 0, false
+}
+
+// pending returns the numbers of the suppressed entries that have not been reported by sample.
+// If all is false, only the counters whose interval has ended are returned, and they are checked
+// at most once per tick; otherwise all the counters are returned.
+// pending of the nil sampler always returns nil.
+func (s *sampler) pending(now time.Time, all bool) []sampleSummary {
+	if s == nil {
+		return nil
+	}
+	tn := now.UnixNano()
+	if !all {
+		flushAt := atomic.LoadInt64(&s.flushAt)
+		if flushAt > tn || !atomic.CompareAndSwapInt64(&s.flushAt, flushAt, tn+s.tick) {
+			return nil
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []sampleSummary
+	for i, summary := range s.unreported {
+		c := &s.counters[i]
+		if !all && atomic.LoadInt64(&c.resetAt) > tn {
+			continue
+		}
+		if summary.suppressed = atomic.SwapUint64(&c.suppressed, 0); summary.suppressed > 0 {
+			list = append(list, summary)
+		}
+		delete(s.unreported, i)
+	}
+	return list
+}
+
+// sampleHash is the FNV-1a hash of level and msg.
+func sampleHash(level Level, msg string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	h ^= uint32(level)
+	h *= prime32
+	for i := 0; i < len(msg); i++ {
+		h ^= uint32(msg[i])
+		h *= prime32
+	}
+	return h
+}
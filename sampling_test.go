@@ -0,0 +1,206 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampler_Sample(t *testing.T) {
+	s := &sampler{tick: int64(time.Second), first: 2, thereafter: 3}
+	now := time.Date(2018, time.May, 20, 8, 20, 30, 0, time.UTC)
+
+	var logged []int
+	for i := 1; i <= 10; i++ {
+		if suppressed, ok := s.sample(ErrorLevel, "msg", now); ok {
+			if suppressed != 0 {
+				t.Errorf("have:%d, want:0", suppressed)
+			}
+			logged = append(logged, i)
+		}
+	}
+	if have, want := len(logged), 4; have != want { // 1, 2, 5, 8
+		t.Errorf("have:%v, want:%d entries", logged, want)
+	}
+
+	// the other level and message are counted separately
+	if _, ok := s.sample(InfoLevel, "msg", now); !ok {
+		t.Error("want true")
+	}
+	if _, ok := s.sample(ErrorLevel, "msg2", now); !ok {
+		t.Error("want true")
+	}
+
+	// next interval
+	suppressed, ok := s.sample(ErrorLevel, "msg", now.Add(time.Second))
+	if !ok {
+		t.Error("want true")
+	}
+	if suppressed != 6 {
+		t.Errorf("have:%d, want:6", suppressed)
+	}
+	if suppressed, _ = s.sample(ErrorLevel, "msg", now.Add(time.Second)); suppressed != 0 {
+		t.Errorf("have:%d, want:0", suppressed)
+	}
+
+	// nil sampler
+	var nilSampler *sampler
+	if _, ok := nilSampler.sample(ErrorLevel, "msg", now); !ok {
+		t.Error("want true")
+	}
+}
+
+func TestSampler_SampleThereafterZero(t *testing.T) {
+	s := &sampler{tick: int64(time.Second), first: 0, thereafter: 0}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, ok := s.sample(ErrorLevel, "msg", now); ok {
+			t.Error("want false")
+		}
+	}
+	// the suppressed count is carried over since the first entry of the next interval is also dropped
+	if _, ok := s.sample(ErrorLevel, "msg", now.Add(time.Second)); ok {
+		t.Error("want false")
+	}
+	if have := s.counters[sampleHash(ErrorLevel, "msg")%_samplerCounters].suppressed; have != 4 {
+		t.Errorf("have:%d, want:4", have)
+	}
+}
+
+func TestSampler_Pending(t *testing.T) {
+	s := &sampler{tick: int64(time.Second), first: 1, thereafter: 0}
+	now := time.Date(2018, time.May, 20, 8, 20, 30, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		s.sample(ErrorLevel, "msg", now)
+	}
+	s.sample(InfoLevel, "msg", now)
+	s.sample(InfoLevel, "msg", now)
+
+	// the interval has not ended
+	if have := s.pending(now, false); len(have) != 0 {
+		t.Errorf("have:%v, want:[]", have)
+	}
+	// checked at most once per tick
+	if have := s.pending(now.Add(time.Second/2), false); len(have) != 0 {
+		t.Errorf("have:%v, want:[]", have)
+	}
+	have := s.pending(now.Add(time.Second), false)
+	if len(have) != 2 {
+		t.Fatalf("have:%v, want 2 summaries", have)
+	}
+	for _, summary := range have {
+		want := uint64(2)
+		if summary.level == InfoLevel {
+			want = 1
+		}
+		if summary.msg != "msg" || summary.suppressed != want {
+			t.Errorf("have:%v, want:%d", summary, want)
+		}
+	}
+	if have := s.pending(now.Add(2*time.Second), false); len(have) != 0 {
+		t.Errorf("have:%v, want:[]", have)
+	}
+	// reported once
+	if suppressed, ok := s.sample(ErrorLevel, "msg", now.Add(2*time.Second)); !ok || suppressed != 0 {
+		t.Errorf("have:(%d, %v), want:(0, true)", suppressed, ok)
+	}
+
+	// all includes the current intervals
+	s.sample(ErrorLevel, "msg", now.Add(2*time.Second))
+	if have := s.pending(now.Add(2*time.Second), true); len(have) != 1 || have[0].suppressed != 1 {
+		t.Errorf("have:%v, want 1 suppressed", have)
+	}
+
+	// nil sampler
+	var nilSampler *sampler
+	if have := nilSampler.pending(now, true); have != nil {
+		t.Errorf("have:%v, want:nil", have)
+	}
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	lg := _New([]Option{
+		WithOutput(&buf),
+		WithFormatter(NewTextFormatter(WithoutFields(TimeField | LocationField | TraceIdField))),
+		WithSampling(time.Hour, 1, 0),
+	})
+	lg = lg.WithField("k", "v").(*logger)
+	for i := 0; i < 5; i++ {
+		lg.Error("msg", "i", i)
+	}
+	lg.Info("msg")
+	want := "level=error, msg=msg, i=0, k=v\n" +
+		"level=info, msg=msg, k=v\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	// start the next interval
+	buf.Reset()
+	counters := &lg.getOptions().sampler.counters
+	for i := range counters {
+		counters[i].resetAt = 0
+	}
+	lg.Error("msg", "i", 5)
+	want = "level=error, msg=msg, k=v, suppressed=4\n" +
+		"level=error, msg=msg, i=5, k=v\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
+
+func TestLogger_SamplingFloodStops(t *testing.T) {
+	var buf bytes.Buffer
+	lg := _New([]Option{
+		WithOutput(&buf),
+		WithFormatter(NewTextFormatter(WithoutFields(TimeField | TraceIdField))),
+		WithSampling(time.Hour, 1, 0),
+	})
+	for i := 0; i < 5; i++ {
+		lg.Error("msg", "i", i)
+	}
+	buf.Reset()
+
+	// the flood stops, the summary is logged by Flush
+	if err := lg.Flush(); err != nil {
+		t.Fatal(err.Error())
+	}
+	want := "level=error, location=???, msg=msg, suppressed=4\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+	buf.Reset()
+	if err := lg.Close(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if have := buf.String(); have != "" {
+		t.Errorf("have:%s, want reported once", have)
+	}
+
+	// the summary is logged by the next entry after the interval ends
+	for i := 0; i < 3; i++ {
+		lg.Error("msg", "i", i)
+	}
+	buf.Reset()
+	opts := lg.getOptions()
+	opts.sampler.counters[sampleHash(ErrorLevel, "msg")%_samplerCounters].resetAt = 0
+	opts.sampler.flushAt = 0
+	lg.Info("other")
+	want = "level=error, location=???, msg=msg, suppressed=3\n"
+	if have := buf.String(); !strings.HasPrefix(have, want) {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
+
+func BenchmarkLogger_Sampling(b *testing.B) {
+	var buf bytes.Buffer
+	lg := New(WithOutput(&buf), WithSampling(time.Hour, 1, 0))
+	lg.Error("msg")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lg.Error("msg", "i", 1)
+	}
+}
@@ -20,6 +20,22 @@ func FatalContext(ctx context.Context, msg string, fields ...interface{}) {
 	Output(1, FatalLevel, msg, fields...)
 }
 
+// PanicContext is a shortcut to the following code:
+//  lg, ok := FromContext(ctx)
+//  if ok {
+//  	lg.Output(1, PanicLevel, msg, fields...)
+//  	return
+//  }
+//  Output(1, PanicLevel, msg, fields...)
+func PanicContext(ctx context.Context, msg string, fields ...interface{}) {
+	lg, ok := FromContext(ctx)
+	if ok {
+		lg.Output(1, PanicLevel, msg, fields...)
+		return
+	}
+	Output(1, PanicLevel, msg, fields...)
+}
+
 // ErrorContext is a shortcut to the following code:
 //  lg, ok := FromContext(ctx)
 //  if ok {
@@ -84,6 +100,22 @@ func DebugContext(ctx context.Context, msg string, fields ...interface{}) {
 	Output(1, DebugLevel, msg, fields...)
 }
 
+// TraceContext is a shortcut to the following code:
+//  lg, ok := FromContext(ctx)
+//  if ok {
+//  	lg.Output(1, TraceLevel, msg, fields...)
+//  	return
+//  }
+//  Output(1, TraceLevel, msg, fields...)
+func TraceContext(ctx context.Context, msg string, fields ...interface{}) {
+	lg, ok := FromContext(ctx)
+	if ok {
+		lg.Output(1, TraceLevel, msg, fields...)
+		return
+	}
+	Output(1, TraceLevel, msg, fields...)
+}
+
 // OutputContext is a shortcut to the following code:
 //  lg, ok := FromContext(ctx)
 //  if ok {
@@ -465,13 +465,13 @@ func TestOutputContextWithoutLogger(t *testing.T) {
 			return
 		}
 	}
-	// debug + 1
+	// trace + 1
 	{
 		var buf bytes.Buffer
 		SetOutput(ConcurrentWriter(&buf))
 		SetFormatter(testJsonFormatter{})
 
-		OutputContext(testWithoutLoggerContext, 0, DebugLevel+1, "debug-msg", "field1-key", "field1-value", "field2-key", "field2-value")
+		OutputContext(testWithoutLoggerContext, 0, TraceLevel+1, "debug-msg", "field1-key", "field1-value", "field2-key", "field2-value")
 		data := buf.Bytes()
 		if len(data) != 0 {
 			t.Errorf("want empty, but now is: %s", data)
@@ -0,0 +1,280 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// NewSlogHandler creates a slog.Handler that writes the records to lg, for example
+//
+//	slog.SetDefault(slog.New(log.NewSlogHandler(lg)))
+//
+// The slog levels are mapped to TraceLevel (below slog.LevelDebug), DebugLevel, InfoLevel, WarnLevel
+// and ErrorLevel (slog.LevelError and above), the records never terminate the program.
+// The attributes of the groups are logged with the dotted keys, for example "http.method",
+// and the top-level attribute "request_id" is logged as Entry.TraceId, it defaults to the trace id of lg.
+//
+// If lg is created by New, the location of the record is the caller of the slog.Logger and
+// the level overrides apply to it, WithStacktrace does not apply to the records.
+func NewSlogHandler(lg Logger) slog.Handler {
+	return &slogHandler{
+		lg: lg,
+	}
+}
+
+type slogHandler struct {
+	lg      Logger
+	fields  []Field // immutable, added by WithAttrs
+	traceId string  // added by WithAttrs
+	prefix  string  // the groups joined with ".", it ends with "." if not empty
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	l, ok := h.lg.(*logger)
+	if !ok {
+		return true
+	}
+	opts := l.getOptions()
+	if opts.levelOverrides.load() != nil { // checked in Handle by the location of the record
+		return true
+	}
+	return opts.isEnabled(levelFromSlog(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	level := levelFromSlog(r.Level)
+	fields := make([]Field, len(h.fields), len(h.fields)+r.NumAttrs())
+	copy(fields, h.fields)
+	traceId := h.traceId
+	r.Attrs(func(a slog.Attr) bool {
+		fields = h.appendAttr(fields, &traceId, h.prefix, a)
+		return true
+	})
+
+	l, ok := h.lg.(*logger)
+	if !ok {
+		if traceId != "" {
+			fields = setField(fields, String(fieldKeyTraceId, traceId))
+		}
+		kvs := make([]interface{}, 0, 2*len(fields))
+		for _, f := range fields {
+			kvs = append(kvs, f.Key, f.Interface())
+		}
+		h.lg.Output(0, level, r.Message, kvs...)
+		return nil
+	}
+	l.outputRecord(l.getOptions(), level, &r, traceId, fields)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.fields = make([]Field, len(h.fields), len(h.fields)+len(attrs))
+	copy(h2.fields, h.fields)
+	for _, a := range attrs {
+		h2.fields = h2.appendAttr(h2.fields, &h2.traceId, h2.prefix, a)
+	}
+	return &h2
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendAttr sets the fields of a in list, the groups are flattened with the dotted keys.
+func (h *slogHandler) appendAttr(list []Field, traceId *string, prefix string, a slog.Attr) []Field {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return list
+		}
+		if a.Key != "" { // the attributes of a group with the empty key are inlined
+			prefix += a.Key + "."
+		}
+		for _, ga := range attrs {
+			list = h.appendAttr(list, traceId, prefix, ga)
+		}
+		return list
+	}
+	if a.Key == "" {
+		return list
+	}
+	if prefix == "" && a.Key == fieldKeyTraceId && v.Kind() == slog.KindString {
+		*traceId = v.String()
+		return list
+	}
+	return setField(list, Any(prefix+a.Key, v.Any()))
+}
+
+// outputRecord outputs the record of slog, see outputWith.
+func (l *logger) outputRecord(opts *options, level Level, r *slog.Record, traceId string, fields []Field) {
+	l.outputWith(opts, -1, r.PC, r.Time, traceId, level, r.Message, func(list []Field) ([]Field, error) {
+		return combineTypedFields(list, fields)
+	})
+}
+
+// levelFromSlog maps the slog level to Level, see NewSlogHandler.
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// slogLevel maps level to the slog level by its severity, each 100 of severity is 4 slog levels:
+// TraceLevel is slog.LevelDebug-4, PanicLevel is slog.LevelError+4 and FatalLevel is slog.LevelError+8.
+func slogLevel(level Level) slog.Level {
+	return slog.Level((InfoLevel.Severity() - level.Severity()) / 25)
+}
+
+// NewSlogLogger creates a Logger that writes the entries to h, for example to log with a third-party slog.Handler.
+//
+// The levels are mapped by their severities (see slogLevel), the fields are the attributes of the records,
+// and the trace id is the attribute "request_id" if it is added by WithField.
+// The logger level (DebugLevel by default) is shared by the derived loggers, and h.Enabled is also checked.
+// SetFormatter and SetOutput do nothing since h formats and writes the records.
+func NewSlogLogger(h slog.Handler) Logger {
+	return &slogLogger{
+		handler: h,
+		level:   NewAtomicLevel(DebugLevel),
+	}
+}
+
+type slogLogger struct {
+	handler slog.Handler
+	level   *AtomicLevel // shared by the derived loggers
+}
+
+func (l *slogLogger) Fatal(msg string, fields ...interface{}) {
+	l.output(1, FatalLevel, msg, fields)
+}
+func (l *slogLogger) Panic(msg string, fields ...interface{}) {
+	l.output(1, PanicLevel, msg, fields)
+}
+func (l *slogLogger) Error(msg string, fields ...interface{}) {
+	l.output(1, ErrorLevel, msg, fields)
+}
+func (l *slogLogger) Warn(msg string, fields ...interface{}) {
+	l.output(1, WarnLevel, msg, fields)
+}
+func (l *slogLogger) Info(msg string, fields ...interface{}) {
+	l.output(1, InfoLevel, msg, fields)
+}
+func (l *slogLogger) Debug(msg string, fields ...interface{}) {
+	l.output(1, DebugLevel, msg, fields)
+}
+func (l *slogLogger) Trace(msg string, fields ...interface{}) {
+	l.output(1, TraceLevel, msg, fields)
+}
+
+func (l *slogLogger) Output(calldepth int, level Level, msg string, fields ...interface{}) {
+	if !isValidLevel(level) {
+		return
+	}
+	if calldepth < 0 {
+		calldepth = 0
+	}
+	l.output(calldepth+1, level, msg, fields)
+}
+
+func (l *slogLogger) output(calldepth int, level Level, msg string, fields []interface{}) {
+	l.handle(calldepth+1, level, msg, fields)
+	if level == PanicLevel {
+		panic(msg)
+	}
+}
+
+func (l *slogLogger) handle(calldepth int, level Level, msg string, fields []interface{}) {
+	if !isLevelEnabled(level, l.level.Level()) {
+		return
+	}
+	ctx := context.Background()
+	sl := slogLevel(level)
+	if !l.handler.Enabled(ctx, sl) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(calldepth+2, pcs[:]) // skip runtime.Callers and handle
+	r := slog.NewRecord(time.Now(), sl, msg, pcs[0])
+	list, err := combineFields(nil, fields)
+	if err != nil {
+		fmt.Fprintf(ConcurrentStderr, "log: failed to combine fields, error=%v, location=%s\n", err, pcLocation(pcs[0]))
+	}
+	for _, f := range list {
+		r.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	if err = l.handler.Handle(ctx, r); err != nil {
+		fmt.Fprintf(ConcurrentStderr, "log: failed to handle slog.Record, error=%v, location=%s\n", err, pcLocation(pcs[0]))
+	}
+}
+
+func (l *slogLogger) WithField(key string, value interface{}) Logger {
+	if key == "" {
+		return l
+	}
+	return &slogLogger{
+		handler: l.handler.WithAttrs([]slog.Attr{slog.Any(key, value)}),
+		level:   l.level,
+	}
+}
+
+func (l *slogLogger) WithFields(fields ...interface{}) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	list, err := combineFields(nil, fields)
+	if err != nil {
+		fmt.Fprintf(ConcurrentStderr, "log: failed to combine fields, error=%v, location=%s\n", err, callerLocation(1))
+	}
+	attrs := make([]slog.Attr, len(list))
+	for i, f := range list {
+		attrs[i] = slog.Any(f.Key, f.Value)
+	}
+	return &slogLogger{
+		handler: l.handler.WithAttrs(attrs),
+		level:   l.level,
+	}
+}
+
+func (l *slogLogger) SetFormatter(Formatter) {}
+func (l *slogLogger) SetOutput(io.Writer)    {}
+
+func (l *slogLogger) SetLevel(level Level) error {
+	if !isValidLevel(level) {
+		return fmt.Errorf("invalid level: %d", level)
+	}
+	l.level.SetLevel(level)
+	return nil
+}
+func (l *slogLogger) SetLevelString(str string) error {
+	level, ok := parseLevelString(str)
+	if !ok {
+		return fmt.Errorf("invalid level string: %q", str)
+	}
+	l.level.SetLevel(level)
+	return nil
+}
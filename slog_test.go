@@ -0,0 +1,119 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var entry Entry
+	lg := New(WithFormatter(&testEntryFormatter{entry: &entry}), WithTraceId("123"), WithLevel(InfoLevel))
+	sl := slog.New(NewSlogHandler(lg.WithField("service", "api")))
+
+	sl.Debug("disabled")
+	if entry.Message != "" {
+		t.Errorf("have:%q, want empty", entry.Message)
+	}
+
+	err := errors.New("timeout")
+	sl.With("a", 1).WithGroup("http").Warn("request", "method", "GET", slog.Group("resp", "status", 504), "error", err)
+	if entry.Level != WarnLevel || entry.Message != "request" || entry.TraceId != "123" {
+		t.Errorf("have:(%v, %q, %q), want:(warning, request, 123)", entry.Level, entry.Message, entry.TraceId)
+	}
+	if !strings.HasPrefix(entry.Location, "log.TestSlogHandler(") || !strings.Contains(entry.Location, "slog_test.go:") {
+		t.Errorf("have:%q, want:log.TestSlogHandler(slog_test.go:...)", entry.Location)
+	}
+	want := []Field{{Key: "service", Value: "api"}, Int64("a", 1), String("http.method", "GET"), Int64("http.resp.status", 504), NamedErr("http.error", err)}
+	if len(entry.FieldList) != len(want) {
+		t.Fatalf("have:%v, want:%v", entry.FieldList, want)
+	}
+	for i := range want {
+		if entry.FieldList[i] != want[i] {
+			t.Errorf("have:%v, want:%v", entry.FieldList[i], want[i])
+		}
+	}
+
+	sl.With(fieldKeyTraceId, "456").Error("failed")
+	if entry.Level != ErrorLevel || entry.TraceId != "456" || len(entry.FieldList) != 1 {
+		t.Errorf("have:(%v, %q, %v), want:(error, 456, [service])", entry.Level, entry.TraceId, entry.FieldList)
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	tests := []struct {
+		level Level
+		slog  slog.Level
+	}{
+		{TraceLevel, slog.LevelDebug - 4},
+		{DebugLevel, slog.LevelDebug},
+		{InfoLevel, slog.LevelInfo},
+		{WarnLevel, slog.LevelWarn},
+		{ErrorLevel, slog.LevelError},
+		{PanicLevel, slog.LevelError + 4},
+		{FatalLevel, slog.LevelError + 8},
+	}
+	for _, v := range tests {
+		if have := slogLevel(v.level); have != v.slog {
+			t.Errorf("%v have:%v, want:%v", v.level, have, v.slog)
+		}
+	}
+	for _, v := range tests[:5] {
+		if have := levelFromSlog(v.slog); have != v.level {
+			t.Errorf("%v have:%v, want:%v", v.slog, have, v.level)
+		}
+	}
+	if have := levelFromSlog(slog.LevelError + 8); have != ErrorLevel {
+		t.Errorf("have:%v, want:%v", have, ErrorLevel)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelDebug - 4,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.Attr{}
+			case slog.SourceKey:
+				src := a.Value.Any().(*slog.Source)
+				return slog.String(a.Key, src.Function[strings.LastIndexByte(src.Function, '.')+1:])
+			}
+			return a
+		},
+	})
+	lg := NewSlogLogger(h)
+	lg2 := lg.WithField("a", 1).WithFields("b", "x")
+
+	lg2.Trace("disabled")
+	lg2.Info("hello", "c", true)
+	if err := lg.SetLevel(TraceLevel); err != nil {
+		t.Fatal(err.Error())
+	}
+	lg2.Trace("enabled")
+	want := "level=INFO source=TestSlogLogger msg=hello a=1 b=x c=true\n" +
+		"level=DEBUG-4 source=TestSlogLogger msg=enabled a=1 b=x\n"
+	if have := buf.String(); have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	buf.Reset()
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("have:%v, want:boom", r)
+			}
+		}()
+		lg.Panic("boom")
+	}()
+	if have := buf.String(); !strings.HasPrefix(have, "level=ERROR+4 source=func") || !strings.HasSuffix(have, " msg=boom\n") {
+		t.Errorf("have:%q, want:level=ERROR+4 source=func... msg=boom", have)
+	}
+}
@@ -4,12 +4,18 @@ import "io"
 
 var _std = _New(nil)
 
-// Fatal logs a message at FatalLevel on the standard logger.
+// Fatal logs a message at FatalLevel on the standard logger, it exits only if SetExitOnFatal is used.
 // For more information see the Logger interface.
 func Fatal(msg string, fields ...interface{}) {
 	_std.output(1, FatalLevel, msg, fields)
 }
 
+// Panic logs a message at PanicLevel on the standard logger and then panics.
+// For more information see the Logger interface.
+func Panic(msg string, fields ...interface{}) {
+	_std.output(1, PanicLevel, msg, fields)
+}
+
 // Error logs a message at ErrorLevel on the standard logger.
 // For more information see the Logger interface.
 func Error(msg string, fields ...interface{}) {
@@ -34,6 +40,12 @@ func Debug(msg string, fields ...interface{}) {
 	_std.output(1, DebugLevel, msg, fields)
 }
 
+// Trace logs a message at TraceLevel on the standard logger.
+// For more information see the Logger interface.
+func Trace(msg string, fields ...interface{}) {
+	_std.output(1, TraceLevel, msg, fields)
+}
+
 // Output logs a message at specified level on the standard logger.
 // For more information see the Logger interface.
 func Output(calldepth int, level Level, msg string, fields ...interface{}) {
@@ -72,3 +84,41 @@ func SetLevel(level Level) error {
 func SetLevelString(str string) error {
 	return _std.SetLevelString(str)
 }
+
+// SetLevelOverrides replaces the level overrides of the standard logger, see WithLevelOverrides.
+func SetLevelOverrides(overrides map[string]Level) {
+	_std.SetLevelOverrides(overrides)
+}
+
+// AddHooks adds the hooks to the standard logger, see WithHooks.
+func AddHooks(hooks ...Hook) {
+	_std.AddHooks(hooks...)
+}
+
+// SetExitOnFatal makes the standard logger call exitFunc(1) after a FatalLevel entry is logged and the standard logger
+// is flushed, os.Exit can be passed as exitFunc. If exitFunc is nil, the standard logger does not exit, which is the default.
+func SetExitOnFatal(exitFunc func(code int)) {
+	_std.setExitOnFatal(exitFunc)
+}
+
+// SetAsync makes the standard logger write the entries on a background goroutine, see WithAsync.
+// If bufferSize <= 0, the standard logger writes the entries synchronously again.
+// The entries buffered before SetAsync is called are written before it returns.
+func SetAsync(bufferSize int, policy OverflowPolicy) error {
+	return _std.setAsync(bufferSize, policy)
+}
+
+// Flush blocks until all the entries buffered by the standard logger have been written.
+func Flush() error {
+	return _std.Flush()
+}
+
+// Close writes all the entries buffered by the standard logger and stops its background goroutine.
+func Close() error {
+	return _std.Close()
+}
+
+// Dropped returns the number of entries dropped because the WithAsync buffer of the standard logger was full.
+func Dropped() uint64 {
+	return _std.Dropped()
+}
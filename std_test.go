@@ -2,6 +2,7 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -178,6 +179,46 @@ func (testJsonFormatter) Format(entry *Entry) ([]byte, error) {
 	return json.Marshal(m)
 }
 
+func TestSetExitOnFatal(t *testing.T) {
+	defer setStdOptionsToDefault()
+	defer SetExitOnFatal(nil)
+
+	var (
+		w    testFlushWriter
+		code = -1
+	)
+	SetOutput(&w)
+	SetFormatter(testMessageFormatter{})
+	SetExitOnFatal(func(c int) {
+		if have := w.String(); !strings.HasSuffix(have, "fatal\n") {
+			t.Errorf("not logged before exit, have:%q", have)
+		}
+		code = c
+	})
+
+	Error("error")
+	if code != -1 || w.flushed != 0 {
+		t.Errorf("have:(%d, %d), want:(-1, 0)", code, w.flushed)
+	}
+	Fatal("fatal")
+	if code != 1 || w.flushed != 1 {
+		t.Errorf("have:(%d, %d), want:(1, 1)", code, w.flushed)
+	}
+	code = -1
+	FatalContext(context.Background(), "fatal")
+	if code != 1 || w.flushed != 2 {
+		t.Errorf("have:(%d, %d), want:(1, 2)", code, w.flushed)
+	}
+
+	// disabled
+	SetExitOnFatal(nil)
+	code = -1
+	Fatal("fatal")
+	if code != -1 || w.flushed != 2 {
+		t.Errorf("have:(%d, %d), want:(-1, 2)", code, w.flushed)
+	}
+}
+
 func TestFatal(t *testing.T) {
 	defer setStdOptionsToDefault()
 
@@ -464,13 +505,13 @@ func TestOutput(t *testing.T) {
 			return
 		}
 	}
-	// debug + 1
+	// trace + 1
 	{
 		var buf bytes.Buffer
 		SetOutput(ConcurrentWriter(&buf))
 		SetFormatter(testJsonFormatter{})
 
-		Output(0, DebugLevel+1, "debug-msg", "field1-key", "field1-value", "field2-key", "field2-value")
+		Output(0, TraceLevel+1, "debug-msg", "field1-key", "field1-value", "field2-key", "field2-value")
 		data := buf.Bytes()
 		if len(data) != 0 {
 			t.Errorf("want empty, but now is: %s", data)
@@ -745,7 +786,7 @@ func TestSetLevel(t *testing.T) {
 		}
 	}
 	{
-		SetLevel(DebugLevel + 1)
+		SetLevel(TraceLevel + 1)
 
 		have := _std.getOptions().level
 		want := InfoLevel
@@ -830,7 +871,7 @@ func TestSetLevelString(t *testing.T) {
 		}
 	}
 	{
-		SetLevelString("panic")
+		SetLevelString("unknown")
 
 		have := _std.getOptions().level
 		want := InfoLevel
@@ -840,7 +881,7 @@ func TestSetLevelString(t *testing.T) {
 		}
 	}
 	{
-		SetLevelString("trace")
+		SetLevelString("unknown")
 
 		have := _std.getOptions().level
 		want := InfoLevel
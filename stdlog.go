@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+)
+
+// NewStdLogAt creates a *log.Logger of the standard library that writes the lines to l at level,
+// for example to log the errors of net/http through l:
+//
+//	srv := &http.Server{ErrorLog: log.NewStdLogAt(lg, log.ErrorLevel)}
+//
+// Each line is logged as the message of an entry, the location of the entry is the caller of the *log.Logger.
+// InfoLevel is used if level is invalid. The flags and the prefix of the returned *log.Logger
+// should not be changed since the entries have their own time and location.
+func NewStdLogAt(l Logger, level Level) *stdlog.Logger {
+	return stdlog.New(newStdLogWriter(l, level), "", 0)
+}
+
+// RedirectStdLog redirects the output of the standard library log package to l at level,
+// see NewStdLogAt, restore reverts the output, the flags and the prefix of the log package.
+//
+//	defer log.RedirectStdLog(lg, log.InfoLevel)()
+func RedirectStdLog(l Logger, level Level) (restore func()) {
+	output, flags, prefix := stdlog.Writer(), stdlog.Flags(), stdlog.Prefix()
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(newStdLogWriter(l, level))
+	return func() {
+		stdlog.SetOutput(output)
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+	}
+}
+
+// _stdLogCalldepth is the calldepth of the caller of *log.Logger relative to stdLogWriter.Write:
+// Write <- (*log.Logger).output <- (*log.Logger).Printf and so on <- the caller.
+const _stdLogCalldepth = 3
+
+type stdLogWriter struct {
+	lg    Logger
+	level Level
+}
+
+func newStdLogWriter(l Logger, level Level) *stdLogWriter {
+	if !isValidLevel(level) {
+		level = InfoLevel
+	}
+	return &stdLogWriter{
+		lg:    l,
+		level: level,
+	}
+}
+
+// Write logs p without the trailing newline added by the log package, it is called once for each line.
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := bytes.TrimSuffix(p, []byte{'\n'})
+	w.lg.Output(_stdLogCalldepth, w.level, string(msg))
+	return len(p), nil
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewStdLogAt(t *testing.T) {
+	var entry Entry
+	lg := New(WithFormatter(&testEntryFormatter{entry: &entry}), WithTraceId("123"))
+	std := NewStdLogAt(lg, ErrorLevel)
+
+	std.Printf("http: TLS handshake error from %s: EOF", "10.0.0.1:5678")
+	if have, want := entry.Message, "http: TLS handshake error from 10.0.0.1:5678: EOF"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if entry.Level != ErrorLevel || entry.TraceId != "123" {
+		t.Errorf("have:(%v, %q), want:(error, 123)", entry.Level, entry.TraceId)
+	}
+	if !strings.HasPrefix(entry.Location, "log.TestNewStdLogAt(") {
+		t.Errorf("have:%q, want:log.TestNewStdLogAt(...)", entry.Location)
+	}
+
+	std.Println("line1\nline2")
+	if have, want := entry.Message, "line1\nline2"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+
+	// invalid level
+	entry = Entry{}
+	NewStdLogAt(lg, TraceLevel+1).Print("hello")
+	if entry.Level != InfoLevel || entry.Message != "hello" {
+		t.Errorf("have:(%v, %q), want:(info, hello)", entry.Level, entry.Message)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var entry Entry
+	lg := New(WithFormatter(&testEntryFormatter{entry: &entry}))
+
+	restore := RedirectStdLog(lg, WarnLevel)
+	stdlog.Print("redirected")
+	if entry.Level != WarnLevel || entry.Message != "redirected" {
+		t.Errorf("have:(%v, %q), want:(warning, redirected)", entry.Level, entry.Message)
+	}
+	if !strings.HasPrefix(entry.Location, "log.TestRedirectStdLog(") {
+		t.Errorf("have:%q, want:log.TestRedirectStdLog(...)", entry.Location)
+	}
+	restore()
+
+	if have := stdlog.Writer(); have != os.Stderr {
+		t.Errorf("have:%v, want:os.Stderr", have)
+	}
+	if have, want := stdlog.Flags(), stdlog.LstdFlags; have != want {
+		t.Errorf("have:%v, want:%v", have, want)
+	}
+
+	var buf bytes.Buffer
+	stdlog.SetOutput(&buf)
+	defer stdlog.SetOutput(os.Stderr)
+	entry = Entry{}
+	stdlog.Print("restored")
+	if entry.Message != "" || !strings.HasSuffix(buf.String(), "restored\n") {
+		t.Errorf("have:(%q, %q), want:(\"\", ...restored)", entry.Message, buf.String())
+	}
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Sink is an output of the logger with its own formatter and level.
+type Sink struct {
+	Output    io.Writer // must be thread-safe, see ConcurrentWriter
+	Formatter Formatter // the default is TextFormatter
+	Level     Level     // the default is DebugLevel
+}
+
+// Tee creates a Logger that writes each entry to all the sinks,
+// it is a shortcut to New(WithSinks(sinks...)).
+func Tee(sinks ...Sink) Logger {
+	return New(WithSinks(sinks...))
+}
+
+// WithSinks makes the logger write each entry to all the sinks instead of the logger output,
+// the entry is formatted once per distinct formatter and an error from one sink
+// does not prevent the entry from being written to the others.
+//
+// The logger level still applies, the entries that pass it are filtered again by the level of each sink.
+// WithLevelOutput and WithLevelFormatter are ignored if there are sinks.
+func WithSinks(sinks ...Sink) Option {
+	return func(o *options) {
+		o.sinks = newSinks(sinks)
+	}
+}
+
+type sinks struct {
+	level          Level // the lowest level of all the sinks
+	needsFieldsMap bool  // whether any formatter needs Entry.Fields
+	list           []Sink
+}
+
+func newSinks(list []Sink) *sinks {
+	s := &sinks{
+		list: make([]Sink, 0, len(list)),
+	}
+	for _, sink := range list {
+		if sink.Output == nil {
+			continue
+		}
+		if sink.Formatter == nil {
+			sink.Formatter = TextFormatter
+		}
+		if !isValidLevel(sink.Level) {
+			sink.Level = DebugLevel
+		}
+		if s.level == invalidLevel || !isLevelEnabled(sink.Level, s.level) {
+			s.level = sink.Level
+		}
+		if needsFieldsMap(sink.Formatter) {
+			s.needsFieldsMap = true
+		}
+		s.list = append(s.list, sink)
+	}
+	if len(s.list) == 0 {
+		return nil
+	}
+	return s
+}
+
+type formattedEntry struct {
+	formatter Formatter
+	buffer    *bytes.Buffer
+	data      []byte
+	err       error
+}
+
+func (s *sinks) write(opts *options, entry *Entry) {
+	pool := getBytesBufferPool()
+
+	var formatted []formattedEntry
+	defer func() {
+		for _, v := range formatted {
+			pool.Put(v.buffer)
+		}
+	}()
+
+	for _, sink := range s.list {
+		if !isLevelEnabled(entry.Level, sink.Level) {
+			continue
+		}
+		var fe *formattedEntry
+		for i := range formatted {
+			if isSameFormatter(formatted[i].formatter, sink.Formatter) {
+				fe = &formatted[i]
+				break
+			}
+		}
+		if fe == nil {
+			buffer := pool.Get()
+			buffer.Reset()
+			entry.Buffer = buffer
+			data, err := sink.Formatter.Format(entry)
+			if err != nil {
+				fmt.Fprintf(ConcurrentStderr, "log: failed to format Entry, error=%v, location=%s\n", err, entry.Location)
+			}
+			formatted = append(formatted, formattedEntry{
+				formatter: sink.Formatter,
+				buffer:    buffer,
+				data:      data,
+				err:       err,
+			})
+			fe = &formatted[len(formatted)-1]
+		}
+		if fe.err != nil {
+			continue
+		}
+		if err := opts.write(sink.Output, entry.Level, fe.data); err != nil {
+			fmt.Fprintf(ConcurrentStderr, "log: failed to write to log, error=%v, location=%s\n", err, entry.Location)
+		}
+	}
+}
+
+func isSameFormatter(a, b Formatter) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
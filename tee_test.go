@@ -0,0 +1,86 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type testErrorWriter struct{}
+
+func (testErrorWriter) Write(p []byte) (n int, err error) {
+	return 0, errors.New("test error")
+}
+
+type testCountingFormatter struct {
+	count *int
+}
+
+func (f testCountingFormatter) Format(entry *Entry) ([]byte, error) {
+	*f.count++
+	return []byte(entry.Message + "\n"), nil
+}
+
+func TestTee(t *testing.T) {
+	var count int
+	formatter := testCountingFormatter{count: &count}
+
+	var buf1, buf2, buf3 bytes.Buffer
+	lg := Tee(
+		Sink{Output: testErrorWriter{}, Formatter: formatter},
+		Sink{Output: &buf1, Formatter: formatter},
+		Sink{Output: &buf2, Formatter: testLevelFormatter{}, Level: ErrorLevel},
+		Sink{Output: &buf3, Formatter: formatter, Level: InfoLevel},
+		Sink{Output: nil}, // ignored
+	)
+	lg.Error("error")
+	lg.Info("info")
+	lg.Debug("debug")
+
+	if have, want := buf1.String(), "error\ninfo\ndebug\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have, want := buf2.String(), "error:error\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if have, want := buf3.String(), "error\ninfo\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+	if count != 3 {
+		t.Errorf("have:%d, want:3", count)
+	}
+}
+
+func TestWithSinks_Level(t *testing.T) {
+	var buf bytes.Buffer
+	lg := _New([]Option{
+		WithSinks(Sink{Output: &buf, Formatter: testMessageFormatter{}, Level: WarnLevel}),
+		WithLevel(ErrorLevel),
+	})
+	if have := lg.getOptions().sinks.level; have != WarnLevel {
+		t.Errorf("have:%v, want:%v", have, WarnLevel)
+	}
+	lg.Warn("warn")
+	lg.Error("error")
+	if have, want := buf.String(), "error\n"; have != want {
+		t.Errorf("have:%q, want:%q", have, want)
+	}
+}
+
+func TestIsSameFormatter(t *testing.T) {
+	if !isSameFormatter(TextFormatter, TextFormatter) {
+		t.Error("want true")
+	}
+	if isSameFormatter(TextFormatter, JsonFormatter) {
+		t.Error("want false")
+	}
+	if isSameFormatter(testSliceFormatter{}, testSliceFormatter{}) {
+		t.Error("want false")
+	}
+}
+
+type testSliceFormatter []int
+
+func (testSliceFormatter) Format(entry *Entry) ([]byte, error) {
+	return nil, nil
+}
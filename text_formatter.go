@@ -4,14 +4,25 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"sort"
+	"math"
 	"strconv"
 	"time"
 )
 
-var TextFormatter Formatter = textFormatter{}
+var TextFormatter Formatter = NewTextFormatter()
 
-type textFormatter struct{}
+// NewTextFormatter creates a Formatter that formats the entry as comma separated key=value pairs.
+func NewTextFormatter(opts ...FormatterOption) Formatter {
+	return textFormatter{
+		opts: newFormatterOptions(opts),
+	}
+}
+
+type textFormatter struct {
+	opts formatterOptions
+}
+
+func (textFormatter) formatsFieldList() {}
 
 func (f textFormatter) Format(entry *Entry) ([]byte, error) {
 	var buffer *bytes.Buffer
@@ -20,24 +31,38 @@ func (f textFormatter) Format(entry *Entry) ([]byte, error) {
 	} else {
 		buffer = bytes.NewBuffer(make([]byte, 0, 16<<10))
 	}
-	f.appendKeyValue(buffer, fieldKeyTime, FormatTimeString(entry.Time.In(_beijingLocation)))
-	f.appendKeyValue(buffer, fieldKeyLevel, entry.Level.String())
-	f.appendKeyValue(buffer, fieldKeyTraceId, entry.TraceId)
-	f.appendKeyValue(buffer, fieldKeyLocation, entry.Location)
-	f.appendKeyValue(buffer, fieldKeyMessage, entry.Message)
-	if fields := entry.Fields; len(fields) > 0 {
-		prefixFieldClashes(fields)
-		keys := make([]string, 0, len(fields))
-		for k := range fields {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			v := fields[k]
-			f.appendKeyValue(buffer, k, v)
+	if key := f.opts.timeKey; key != "" {
+		f.appendKeyValue(buffer, key, f.opts.formatTime(entry.Time))
+	}
+	if key := f.opts.levelKey; key != "" {
+		if color := entry.Level.Color(); f.opts.levelColor && color != NoColor {
+			f.appendKeyValue(buffer, key, "\x1b["+strconv.Itoa(int(color))+"m"+entry.Level.String()+"\x1b[0m")
+		} else {
+			f.appendKeyValue(buffer, key, entry.Level.String())
 		}
 	}
+	if key := f.opts.traceIdKey; key != "" {
+		f.appendKeyValue(buffer, key, entry.TraceId)
+	}
+	if key := f.opts.locationKey; key != "" {
+		f.appendKeyValue(buffer, key, entry.Location)
+	}
+	if key := f.opts.messageKey; key != "" {
+		f.appendKeyValue(buffer, key, entry.Message)
+	}
+	var array [32]Field
+	fields := f.opts.appendFields(array[:0], entry)
+	for i := range fields {
+		f.appendField(buffer, &fields[i])
+	}
 	buffer.WriteByte('\n')
+	if f.opts.stackKey != "" {
+		for _, frame := range entry.Stack {
+			buffer.WriteByte('\t')
+			buffer.WriteString(frame)
+			buffer.WriteByte('\n')
+		}
+	}
 	return buffer.Bytes(), nil
 }
 
@@ -50,6 +75,34 @@ func (f textFormatter) appendKeyValue(b *bytes.Buffer, key string, value interfa
 	f.appendValue(b, value)
 }
 
+func (f textFormatter) appendField(b *bytes.Buffer, field *Field) {
+	if b.Len() > 0 {
+		b.WriteString(", ")
+	}
+	b.WriteString(field.Key)
+	b.WriteByte('=')
+
+	var scratch [64]byte
+	switch field.Type {
+	case StringType:
+		b.WriteString(field.String)
+	case Int64Type:
+		b.Write(strconv.AppendInt(scratch[:0], field.Integer, 10))
+	case Uint64Type:
+		b.Write(strconv.AppendUint(scratch[:0], uint64(field.Integer), 10))
+	case Float64Type:
+		b.Write(strconv.AppendFloat(scratch[:0], math.Float64frombits(uint64(field.Integer)), 'g', -1, 64))
+	case BoolType:
+		b.Write(strconv.AppendBool(scratch[:0], field.Integer == 1))
+	case DurationType:
+		b.WriteString(time.Duration(field.Integer).String())
+	case ObjectType:
+		b.WriteString(JSON(field.Value))
+	default:
+		f.appendValue(b, field.Interface())
+	}
+}
+
 func (f textFormatter) appendValue(b *bytes.Buffer, value interface{}) {
 	var stringVal string
 	switch v := value.(type) {
@@ -71,67 +124,5 @@ const (
 	fieldKeyTraceId  = "request_id"
 	fieldKeyLocation = "location"
 	fieldKeyMessage  = "msg"
+	fieldKeyStack    = "stack"
 )
-
-func prefixFieldClashes(data map[string]interface{}) {
-	if v, ok := data[fieldKeyTime]; ok {
-		delete(data, fieldKeyTime)
-		newKey := "fields." + fieldKeyTime
-		for key, i := newKey, 2; ; i++ {
-			_, ok = data[key]
-			if !ok {
-				data[key] = v
-				break
-			}
-			key = newKey + "." + strconv.Itoa(i)
-		}
-	}
-	if v, ok := data[fieldKeyLevel]; ok {
-		delete(data, fieldKeyLevel)
-		newKey := "fields." + fieldKeyLevel
-		for key, i := newKey, 2; ; i++ {
-			_, ok = data[key]
-			if !ok {
-				data[key] = v
-				break
-			}
-			key = newKey + "." + strconv.Itoa(i)
-		}
-	}
-	if v, ok := data[fieldKeyTraceId]; ok {
-		delete(data, fieldKeyTraceId)
-		newKey := "fields." + fieldKeyTraceId
-		for key, i := newKey, 2; ; i++ {
-			_, ok = data[key]
-			if !ok {
-				data[key] = v
-				break
-			}
-			key = newKey + "." + strconv.Itoa(i)
-		}
-	}
-	if v, ok := data[fieldKeyLocation]; ok {
-		delete(data, fieldKeyLocation)
-		newKey := "fields." + fieldKeyLocation
-		for key, i := newKey, 2; ; i++ {
-			_, ok = data[key]
-			if !ok {
-				data[key] = v
-				break
-			}
-			key = newKey + "." + strconv.Itoa(i)
-		}
-	}
-	if v, ok := data[fieldKeyMessage]; ok {
-		delete(data, fieldKeyMessage)
-		newKey := "fields." + fieldKeyMessage
-		for key, i := newKey, 2; ; i++ {
-			_, ok = data[key]
-			if !ok {
-				data[key] = v
-				break
-			}
-			key = newKey + "." + strconv.Itoa(i)
-		}
-	}
-}
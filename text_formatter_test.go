@@ -3,6 +3,7 @@ package log
 import (
 	"encoding/json"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -41,6 +42,33 @@ func TestTextFormatter_Format(t *testing.T) {
 	}
 }
 
+// prefixFieldClashes renames the fields of data that clash with the built-in fields,
+// it is the map based counterpart of formatterOptions.clashFreeKey for the test formatters.
+func prefixFieldClashes(data map[string]interface{}) {
+	prefixFieldClash(data, fieldKeyTime)
+	prefixFieldClash(data, fieldKeyLevel)
+	prefixFieldClash(data, fieldKeyTraceId)
+	prefixFieldClash(data, fieldKeyLocation)
+	prefixFieldClash(data, fieldKeyMessage)
+}
+
+func prefixFieldClash(data map[string]interface{}, fieldKey string) {
+	v, ok := data[fieldKey]
+	if !ok {
+		return
+	}
+	delete(data, fieldKey)
+	newKey := "fields." + fieldKey
+	for key, i := newKey, 2; ; i++ {
+		_, ok = data[key]
+		if !ok {
+			data[key] = v
+			break
+		}
+		key = newKey + "." + strconv.Itoa(i)
+	}
+}
+
 func TestPrefixFieldClashes(t *testing.T) {
 	m := map[string]interface{}{
 		"time":           "time",
@@ -62,3 +90,31 @@ func TestPrefixFieldClashes(t *testing.T) {
 		return
 	}
 }
+
+func TestTextFormatter_FormatStack(t *testing.T) {
+	entry := &Entry{
+		Level:     ErrorLevel,
+		Message:   "msg",
+		Stack:     []string{"main.f(main.go:10)", "main.main(main.go:5)"},
+		FieldList: []Field{String("stack", "v")},
+	}
+	formatter := NewTextFormatter(WithoutFields(TimeField | TraceIdField | LocationField))
+	have, err := formatter.Format(entry)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	want := "level=error, msg=msg, fields.stack=v\n" +
+		"\tmain.f(main.go:10)\n" +
+		"\tmain.main(main.go:5)\n"
+	if string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	// without stack
+	formatter = NewTextFormatter(WithoutFields(TimeField | TraceIdField | LocationField | StackField))
+	have, _ = formatter.Format(entry)
+	if want := "level=error, msg=msg, stack=v\n"; string(have) != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+}
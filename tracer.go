@@ -7,3 +7,19 @@ var _ trace.Tracer = (*logger)(nil)
 func (l *logger) TraceId() string {
 	return l.getOptions().traceId
 }
+
+// WithTraceIdLogger creates a new Logger from lg with traceId, lg is not modified.
+// The trace id is added as the field "request_id" if lg is not created by New.
+func WithTraceIdLogger(lg Logger, traceId string) Logger {
+	l, ok := lg.(*logger)
+	if !ok {
+		return lg.WithField(fieldKeyTraceId, traceId)
+	}
+	opts := *l.getOptions()
+	opts.traceId = traceId
+	nl := &logger{
+		fields: l.fields,
+	}
+	nl.setOptions(&opts)
+	return nl
+}
@@ -14,3 +14,16 @@ func TestIsTracer(t *testing.T) {
 		return
 	}
 }
+
+func TestWithTraceIdLogger(t *testing.T) {
+	var entry Entry
+	lg := New(WithTraceId("123"), WithFormatter(&testEntryFormatter{entry: &entry})).WithField("k", "v")
+	lg2 := WithTraceIdLogger(lg, "456")
+	if have := lg.(trace.Tracer).TraceId(); have != "123" {
+		t.Errorf("have:%q, want:123", have)
+	}
+	lg2.Info("msg")
+	if entry.TraceId != "456" || len(entry.FieldList) != 1 || entry.FieldList[0].Key != "k" {
+		t.Errorf("have:(%q, %v), want:(456, [k])", entry.TraceId, entry.FieldList)
+	}
+}
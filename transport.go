@@ -0,0 +1,102 @@
+package log
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/KeKe-Li/log/trace"
+)
+
+type TransportOption func(*transportOptions)
+
+// WithCallLog makes the Transport log each call at level through the logger of the request context (see FromContext),
+// the calls failed with an error or the status 500 and above are logged at ErrorLevel.
+func WithCallLog(level Level) TransportOption {
+	return func(o *transportOptions) {
+		if !isValidLevel(level) {
+			return
+		}
+		o.level = level
+	}
+}
+
+type transportOptions struct {
+	level Level // invalidLevel means no call log
+}
+
+// CallLogMessage is the message of the call log entries of Transport.
+const CallLogMessage = "http call"
+
+// Transport returns an http.RoundTripper that propagates the trace id of the outgoing requests, for example
+//
+//	client := &http.Client{Transport: log.Transport(nil, log.WithCallLog(log.InfoLevel))}
+//	req = req.WithContext(log.NewContext(ctx, lg))
+//	resp, err := client.Do(req)
+//
+// The trace id is taken from the request context by trace.FromContext, or the TraceId of the logger
+// of the request context (see FromContext), it is set as the header X-Request-Id if the request does not have it.
+// base is the underlying http.RoundTripper, http.DefaultTransport is used if it is nil.
+//
+// With WithCallLog, each call is logged with the fields method, url (without the user info), status, latency
+// and error (if the call failed).
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &transport{
+		base: base,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&t.opts)
+	}
+	return t
+}
+
+type transport struct {
+	base http.RoundTripper
+	opts transportOptions
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	lg, hasLogger := FromContext(ctx)
+	traceId, ok := trace.FromContext(ctx)
+	if !ok && hasLogger {
+		if tracer, ok := lg.(trace.Tracer); ok {
+			traceId = tracer.TraceId()
+		}
+	}
+	if traceId != "" && req.Header.Get(trace.TraceIdHeaderKey) == "" {
+		req = req.Clone(ctx) // the RoundTripper must not modify the request
+		req.Header.Set(trace.TraceIdHeaderKey, traceId)
+	}
+	if t.opts.level == invalidLevel || !hasLogger {
+		return t.base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	u := *req.URL
+	u.User = nil
+	fields := []interface{}{
+		"method", req.Method,
+		"url", u.String(),
+	}
+	level := t.opts.level
+	if err != nil {
+		level = ErrorLevel
+		fields = append(fields, "latency", latency, "error", err)
+	} else {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			level = ErrorLevel
+		}
+		fields = append(fields, "status", resp.StatusCode, "latency", latency)
+	}
+	lg.Output(0, level, CallLogMessage, fields...)
+	return resp, err
+}
@@ -0,0 +1,247 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FieldType decides how the value of a Field is stored and encoded.
+type FieldType uint8
+
+const (
+	// UnknownType is the type of the fields passed as alternating keys and values, the value is stored in Field.Value.
+	UnknownType FieldType = iota
+	StringType
+	Int64Type
+	Uint64Type
+	Float64Type
+	BoolType
+	DurationType
+	TimeType
+
+	// ErrorType is the type of the fields created by Err and NamedErr, the error is stored in Field.Value.
+	ErrorType
+
+	// StringerType is the type of the fields created by Stringer, the fmt.Stringer is stored in Field.Value.
+	StringerType
+
+	// ObjectType is the type of the fields created by Object, the value is stored in Field.Value
+	// and it is always encoded as JSON, also by TextFormatter.
+	ObjectType
+)
+
+func String(key string, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: Int64Type, Integer: int64(value)}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: Int64Type, Integer: value}
+}
+
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: Uint64Type, Integer: int64(value)}
+}
+
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: Float64Type, Integer: int64(math.Float64bits(value))}
+}
+
+func Bool(key string, value bool) Field {
+	var n int64
+	if value {
+		n = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: n}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time creates a Field with the time, the monotonic clock reading is discarded.
+func Time(key string, value time.Time) Field {
+	if year := value.Year(); year < 1678 || year > 2261 { // out of the range of UnixNano
+		return Field{Key: key, Value: value}
+	}
+	return Field{Key: key, Type: TimeType, Integer: value.UnixNano(), Value: value.Location()}
+}
+
+// Err creates a Field with the key "error".
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+func NamedErr(key string, err error) Field {
+	return Field{Key: key, Type: ErrorType, Value: err}
+}
+
+func Stringer(key string, value fmt.Stringer) Field {
+	return Field{Key: key, Type: StringerType, Value: value}
+}
+
+// Object creates a Field that is always encoded as JSON with the tagged struct fields redacted, see ObjectType and JSON.
+func Object(key string, value interface{}) Field {
+	return Field{Key: key, Type: ObjectType, Value: value}
+}
+
+// Any creates a typed Field if the type of value is supported, otherwise a Field with UnknownType.
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int8:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint8:
+		return Uint64(key, uint64(v))
+	case uint16:
+		return Uint64(key, uint64(v))
+	case uint32:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	default:
+		return Field{Key: key, Value: value}
+	}
+}
+
+// Interface returns the value of the field.
+func (f Field) Interface() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case Int64Type:
+		return f.Integer
+	case Uint64Type:
+		return uint64(f.Integer)
+	case Float64Type:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.time()
+	default:
+		return f.Value
+	}
+}
+
+func (f Field) time() time.Time {
+	t := time.Unix(0, f.Integer)
+	if loc, ok := f.Value.(*time.Location); ok && loc != nil {
+		return t.In(loc)
+	}
+	return t
+}
+
+// FieldLogger is the typed variant of Logger, the Logger returned by New implements it.
+//
+// The fields are passed as Field, so the mistakes in keys are caught at compile time
+// and the values are not boxed into interface{}.
+type FieldLogger interface {
+	Logger
+
+	// FatalF logs a message at FatalLevel, see Logger.Fatal.
+	FatalF(msg string, fields ...Field)
+
+	// PanicF logs a message at PanicLevel and then panics, see Logger.Panic.
+	PanicF(msg string, fields ...Field)
+
+	// ErrorF logs a message at ErrorLevel.
+	ErrorF(msg string, fields ...Field)
+
+	// WarnF logs a message at WarnLevel.
+	WarnF(msg string, fields ...Field)
+
+	// InfoF logs a message at InfoLevel.
+	InfoF(msg string, fields ...Field)
+
+	// DebugF logs a message at DebugLevel.
+	DebugF(msg string, fields ...Field)
+
+	// TraceF logs a message at TraceLevel.
+	TraceF(msg string, fields ...Field)
+
+	// OutputF logs a message at specified level, see Logger.Output.
+	OutputF(calldepth int, level Level, msg string, fields ...Field)
+
+	// With creates a new FieldLogger from the current FieldLogger and adds the fields to it.
+	With(fields ...Field) FieldLogger
+}
+
+var _ FieldLogger = (*logger)(nil)
+
+// NewFieldLogger is the same as New but returns a FieldLogger.
+func NewFieldLogger(opts ...Option) FieldLogger { return _New(opts) }
+
+func (l *logger) FatalF(msg string, fields ...Field) {
+	l.outputFields(1, FatalLevel, msg, fields)
+}
+func (l *logger) PanicF(msg string, fields ...Field) {
+	l.outputFields(1, PanicLevel, msg, fields)
+}
+func (l *logger) ErrorF(msg string, fields ...Field) {
+	l.outputFields(1, ErrorLevel, msg, fields)
+}
+func (l *logger) WarnF(msg string, fields ...Field) {
+	l.outputFields(1, WarnLevel, msg, fields)
+}
+func (l *logger) InfoF(msg string, fields ...Field) {
+	l.outputFields(1, InfoLevel, msg, fields)
+}
+func (l *logger) DebugF(msg string, fields ...Field) {
+	l.outputFields(1, DebugLevel, msg, fields)
+}
+func (l *logger) TraceF(msg string, fields ...Field) {
+	l.outputFields(1, TraceLevel, msg, fields)
+}
+
+func (l *logger) OutputF(calldepth int, level Level, msg string, fields ...Field) {
+	if !isValidLevel(level) {
+		return
+	}
+	if calldepth < 0 {
+		calldepth = 0
+	}
+	l.outputFields(calldepth+1, level, msg, fields)
+}
+
+func (l *logger) With(fields ...Field) FieldLogger {
+	if len(fields) == 0 {
+		return l
+	}
+	list, err := combineTypedFields(l.fields, fields)
+	if err != nil {
+		fmt.Fprintf(ConcurrentStderr, "log: failed to combine fields, error=%v, location=%s\n", err, callerLocation(1))
+	}
+	nl := &logger{
+		fields: list,
+	}
+	nl.setOptions(l.getOptions())
+	return nl
+}
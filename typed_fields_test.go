@@ -0,0 +1,98 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestField_Interface(t *testing.T) {
+	now := time.Date(2018, time.May, 20, 8, 20, 30, 666000000, _beijingLocation)
+	err := errors.New("error")
+	tests := []struct {
+		field Field
+		want  interface{}
+	}{
+		{String("k", "v"), "v"},
+		{Int("k", -1), int64(-1)},
+		{Int64("k", -2), int64(-2)},
+		{Uint64("k", 1<<63), uint64(1 << 63)},
+		{Float64("k", 1.5), 1.5},
+		{Bool("k", true), true},
+		{Bool("k", false), false},
+		{Duration("k", time.Second), time.Second},
+		{Time("k", now), now},
+		{Time("k", time.Time{}), time.Time{}},
+		{Err(err), err},
+		{Stringer("k", testStringer{}), testStringer{}},
+		{Object("k", []int{1}), []int{1}},
+		{Any("k", "v"), "v"},
+		{Any("k", int32(3)), int64(3)},
+		{Any("k", uint8(4)), uint64(4)},
+		{Any("k", []int{1}), []int{1}},
+	}
+	for _, v := range tests {
+		have := v.field.Interface()
+		if !reflect.DeepEqual(have, v.want) {
+			if ht, ok := have.(time.Time); !ok || !ht.Equal(v.want.(time.Time)) || ht.Location() != v.want.(time.Time).Location() {
+				t.Errorf("field:%+v, have:%v, want:%v", v.field, have, v.want)
+			}
+		}
+	}
+	if f := Err(err); f.Key != "error" || f.Type != ErrorType {
+		t.Errorf("not expected field: %+v", f)
+	}
+	if f := Any("k", err); f.Type != ErrorType {
+		t.Errorf("not expected field: %+v", f)
+	}
+}
+
+func TestFieldLogger(t *testing.T) {
+	var buf bytes.Buffer
+	lg := NewFieldLogger(
+		WithOutput(&buf),
+		WithFormatter(NewJsonFormatter(WithoutFields(TimeField|LocationField), WithFieldOrder(InsertionFieldOrder))),
+	)
+	lg = lg.With(String("s", "v"), Int("n", 1))
+	lg.InfoF("msg",
+		Float64("f", 0.5),
+		Bool("b", true),
+		Duration("d", 1500*time.Millisecond),
+		Time("t", time.Date(2018, time.May, 20, 8, 20, 30, 666000000, time.UTC)),
+		Err(errors.New("error")),
+		NamedErr("nil", nil),
+		NamedErr("nil_ptr", (*testError)(nil)),
+		Stringer("stringer", testStringer{}),
+		Stringer("nil_stringer", (*testPtrStringer)(nil)),
+		Object("o", map[string]int{"a": 1}),
+		Uint64("u", 2),
+		Int("n", 3),
+		String("", "ignored"),
+	)
+	want := `{"level":"info","request_id":"","msg":"msg","s":"v","n":3,"f":0.5,"b":true,"d":1500000000,` +
+		`"t":"2018-05-20T08:20:30.666Z","error":"error","nil":null,"nil_ptr":null,"stringer":"stringer","nil_stringer":null,"o":{"a":1},"u":2}` + "\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	buf.Reset()
+	lg.SetFormatter(NewTextFormatter(WithoutFields(TimeField|LocationField|TraceIdField), WithFieldOrder(InsertionFieldOrder)))
+	lg.WarnF("msg", Float64("f", 0.5), Object("o", map[string]int{"a": 1}), Duration("d", time.Second))
+	want = `level=warning, msg=msg, s=v, n=1, f=0.5, o={"a":1}, d=1s` + "\n"
+	if have := buf.String(); have != want {
+		t.Errorf("\nhave:%s\nwant:%s", have, want)
+	}
+
+	// custom formatter gets Entry.Fields
+	var entry Entry
+	lg.SetFormatter(&testEntryFormatter{entry: &entry})
+	lg.OutputF(0, ErrorLevel, "msg", Int64("n", 2))
+	if want := map[string]interface{}{"s": "v", "n": int64(2)}; !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("have:%v, want:%v", entry.Fields, want)
+	}
+	if entry.Level != ErrorLevel {
+		t.Errorf("have:%v, want:%v", entry.Level, ErrorLevel)
+	}
+}